@@ -1,20 +1,43 @@
 package enet
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
 	"unsafe"
 )
 
 // #include <enet/enet.h>
 import "C"
 
-// Address specifies a portable internet address structure.
+// Address specifies a portable internet address structure. Enet only supports IPv4
+// addresses. Address implements net.Addr, so it can be used wherever the standard
+// library expects an address.
 type Address interface {
 	SetHostAny()
-	SetHost(ip string)
+
+	// SetHost sets the host of the address, resolving it if it's a hostname.
+	SetHost(hostname string) error
 	SetPort(port uint16)
 
+	// Hostname returns the hostname of the address through a reverse DNS lookup, or its
+	// IP if it has no hostname. This blocks until the lookup is done.
+	Hostname() (string, error)
+
+	// Network returns "udp".
+	Network() string
+
+	// String returns the address as ip:port.
 	String() string
 	GetPort() uint16
+
+	// AddrPort returns the address as a netip.AddrPort.
+	AddrPort() netip.AddrPort
+
+	// UDPAddr returns the address as a *net.UDPAddr.
+	UDPAddr() *net.UDPAddr
 }
 
 type enetAddress struct {
@@ -25,41 +48,136 @@ func (addr *enetAddress) SetHostAny() {
 	addr.cAddr.host = C.ENET_HOST_ANY
 }
 
-func (addr *enetAddress) SetHost(hostname string) {
+func (addr *enetAddress) SetHost(hostname string) error {
+	// Literal IPs don't need a DNS lookup.
+	if ip, err := netip.ParseAddr(hostname); err == nil && ip.Is4() {
+		return addr.setHostIP(hostname)
+	}
+
 	cHostname := C.CString(hostname)
-	C.enet_address_set_host(
+	defer C.free(unsafe.Pointer(cHostname))
+
+	status := C.enet_address_set_host(
 		&addr.cAddr,
 		cHostname,
 	)
-	C.free(unsafe.Pointer(cHostname))
+
+	if status != 0 {
+		return fmt.Errorf("%w: %s", ErrResolveFailed, hostname)
+	}
+	return nil
+}
+
+// setHostIP sets the host of the address to an IPv4 address in dotted notation, without
+// touching DNS.
+func (addr *enetAddress) setHostIP(ip string) error {
+	cIP := C.CString(ip)
+	defer C.free(unsafe.Pointer(cIP))
+
+	status := C.enet_address_set_host_ip(
+		&addr.cAddr,
+		cIP,
+	)
+
+	if status != 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, ip)
+	}
+	return nil
 }
 
 func (addr *enetAddress) SetPort(port uint16) {
 	addr.cAddr.port = (C.enet_uint16)(port)
 }
 
-func (addr *enetAddress) String() string {
-	buffer := C.malloc(32)
-	C.enet_address_get_host_ip(
+func (addr *enetAddress) Hostname() (string, error) {
+	// NI_MAXHOST, the longest hostname getnameinfo returns
+	const size = 1025
+
+	buffer := C.malloc(size)
+	defer C.free(buffer)
+
+	status := C.enet_address_get_host(
 		&addr.cAddr,
 		(*C.char)(buffer),
-		32,
+		size,
 	)
-	ret := C.GoString((*C.char)(buffer))
-	C.free(buffer)
-	return ret
+
+	if status != 0 {
+		return "", fmt.Errorf("%w: %s", ErrResolveFailed, addr.AddrPort().Addr())
+	}
+	return C.GoString((*C.char)(buffer)), nil
+}
+
+func (addr *enetAddress) Network() string {
+	return "udp"
+}
+
+func (addr *enetAddress) String() string {
+	return addr.AddrPort().String()
 }
 
 func (addr *enetAddress) GetPort() uint16 {
 	return uint16(addr.cAddr.port)
 }
 
-// NewAddress creates a new address
-func NewAddress(ip string, port uint16) Address {
+func (addr *enetAddress) AddrPort() netip.AddrPort {
+	// The host is stored in network byte order, so its bytes in memory are the IP.
+	ip := *(*[4]byte)(unsafe.Pointer(&addr.cAddr.host))
+	return netip.AddrPortFrom(netip.AddrFrom4(ip), addr.GetPort())
+}
+
+func (addr *enetAddress) UDPAddr() *net.UDPAddr {
+	return net.UDPAddrFromAddrPort(addr.AddrPort())
+}
+
+// NewAddress creates a new address, resolving the host if it's a hostname
+func NewAddress(hostname string, port uint16) (Address, error) {
 	ret := enetAddress{}
-	ret.SetHost(ip)
+	if err := ret.SetHost(hostname); err != nil {
+		return nil, err
+	}
 	ret.SetPort(port)
-	return &ret
+	return &ret, nil
+}
+
+// ParseAddress parses an address in the form ip:port, where ip is an IPv4 address in
+// dotted notation. Unlike NewAddress, this never performs a DNS lookup.
+func ParseAddress(ipPort string) (Address, error) {
+	ip, portString, err := net.SplitHostPort(ipPort)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, ipPort)
+	}
+
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, ipPort)
+	}
+
+	ret := enetAddress{}
+	if err := ret.setHostIP(ip); err != nil {
+		return nil, err
+	}
+	ret.SetPort(uint16(port))
+	return &ret, nil
+}
+
+// ResolveAddress creates a new address like NewAddress, but resolves the host with the
+// given resolver, so the lookup can be cancelled through the context. A nil resolver
+// uses net.DefaultResolver.
+func ResolveAddress(ctx context.Context, resolver *net.Resolver, hostname string, port uint16) (Address, error) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	ips, err := resolver.LookupNetIP(ctx, "ip4", hostname)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrResolveFailed, err)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrResolveFailed, hostname)
+	}
+
+	return AddressFromAddrPort(netip.AddrPortFrom(ips[0], port))
 }
 
 // NewListenAddress makes a new address ready for listening on ENET_HOST_ANY
@@ -69,3 +187,63 @@ func NewListenAddress(port uint16) Address {
 	ret.SetPort(port)
 	return &ret
 }
+
+// AddressFromAddrPort converts a netip.AddrPort to an address. An invalid or unspecified
+// IP, such as 0.0.0.0 or [::], means ENET_HOST_ANY. Returns ErrInvalidAddress for any
+// other IP that's not an IPv4 address.
+func AddressFromAddrPort(addrPort netip.AddrPort) (Address, error) {
+	ip := addrPort.Addr().Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() {
+		return NewListenAddress(addrPort.Port()), nil
+	}
+	if !ip.Is4() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, addrPort)
+	}
+
+	ret := enetAddress{}
+	*(*[4]byte)(unsafe.Pointer(&ret.cAddr.host)) = ip.As4()
+	ret.SetPort(addrPort.Port())
+	return &ret, nil
+}
+
+// AddressFromUDPAddr converts a *net.UDPAddr to an address. A nil or unspecified IP means
+// ENET_HOST_ANY, so &net.UDPAddr{Port: port} listens on all interfaces. Returns
+// ErrInvalidAddress for any other IP that's not an IPv4 address.
+func AddressFromUDPAddr(udpAddr *net.UDPAddr) (Address, error) {
+	return AddressFromAddrPort(udpAddr.AddrPort())
+}
+
+// cAddressFor returns the enet address of a standard library address, or nil if addr is
+// nil. Addresses other than Address and *net.UDPAddr are parsed from their string form.
+func cAddressFor(addr net.Addr) (*C.struct__ENetAddress, error) {
+	switch addr := addr.(type) {
+	case nil:
+		return nil, nil
+
+	case *enetAddress:
+		if addr == nil {
+			return nil, nil
+		}
+		return &addr.cAddr, nil
+
+	case *net.UDPAddr:
+		if addr == nil {
+			return nil, nil
+		}
+		ret, err := AddressFromUDPAddr(addr)
+		if err != nil {
+			return nil, err
+		}
+		return &(ret.(*enetAddress)).cAddr, nil
+	}
+
+	addrPort, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, addr)
+	}
+	ret, err := AddressFromAddrPort(addrPort)
+	if err != nil {
+		return nil, err
+	}
+	return &(ret.(*enetAddress)).cAddr, nil
+}
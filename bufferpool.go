@@ -0,0 +1,106 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// BufferPool creates packets with PacketFlagNoAllocate, backed by reusable C buffers of a
+// fixed size. When enet is done with a packet, its buffer is returned to the pool for the
+// next packet, so sending doesn't allocate memory for the data of every packet.
+type BufferPool struct {
+	size int
+
+	mutex  sync.Mutex
+	free   []unsafe.Pointer
+	closed bool
+}
+
+// NewBufferPool creates a pool of buffers that can hold up to size bytes each.
+func NewBufferPool(size int) (*BufferPool, error) {
+	if size <= 0 {
+		return nil, errors.New("buffer size must be positive")
+	}
+
+	return &BufferPool{
+		size: size,
+	}, nil
+}
+
+// NewPacket creates a packet of the given length backed by a buffer from the pool. The
+// data of the packet is uninitialized, and can be filled in through Packet.Bytes. The
+// packet can't be resized beyond the buffer size of the pool.
+func (pool *BufferPool) NewPacket(length int, flags PacketFlags) (Packet, error) {
+	if length < 0 || length > pool.size {
+		return nil, fmt.Errorf("packet length must be between 0 and %d", pool.size)
+	}
+
+	buffer := pool.get()
+	if buffer == nil {
+		return nil, errors.New("unable to allocate buffer")
+	}
+
+	noteAllocation()
+	packet := C.enet_packet_create(
+		buffer,
+		(C.size_t)(length),
+		(C.enet_uint32)(flags|PacketFlagNoAllocate),
+	)
+
+	if packet == nil {
+		pool.put(buffer)
+		return nil, errors.New("unable to create packet")
+	}
+
+	ret := &enetPacket{
+		cPacket:  packet,
+		capacity: pool.size,
+	}
+	ret.freeState().release = func() {
+		pool.put(buffer)
+	}
+	debugTrackNewPacket(ret)
+	return ret, nil
+}
+
+// Close frees all buffers in the pool. Buffers of packets that are still alive are freed
+// once enet is done with them.
+func (pool *BufferPool) Close() {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	for _, buffer := range pool.free {
+		C.free(buffer)
+	}
+	pool.free = nil
+	pool.closed = true
+}
+
+func (pool *BufferPool) get() unsafe.Pointer {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if n := len(pool.free); n > 0 {
+		buffer := pool.free[n-1]
+		pool.free = pool.free[:n-1]
+		return buffer
+	}
+
+	return C.malloc((C.size_t)(pool.size))
+}
+
+func (pool *BufferPool) put(buffer unsafe.Pointer) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if pool.closed {
+		C.free(buffer)
+		return
+	}
+
+	pool.free = append(pool.free, buffer)
+}
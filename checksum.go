@@ -0,0 +1,96 @@
+package enet
+
+/*
+#include <enet/enet.h>
+
+// enet doesn't pass a context to checksum callbacks, so the host being serviced is kept
+// in a thread-local variable while a Go checksum function may be called.
+static __thread ENetHost * goChecksumCurrentHost;
+
+void goChecksumSetHost(ENetHost * host) {
+	goChecksumCurrentHost = host;
+}
+
+ENetHost * goChecksumHost(void) {
+	return goChecksumCurrentHost;
+}
+
+enet_uint32 goChecksum(const ENetBuffer *, size_t);
+*/
+import "C"
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// ChecksumFunc computes the checksum of the concatenation of the given buffers
+type ChecksumFunc func(buffers [][]byte) uint32
+
+// checksumFuncs maps hosts to the Go checksum function they use.
+var checksumFuncs sync.Map
+
+// CRC32 computes the CRC32 checksum enet uses for the concatenation of the given buffers.
+func CRC32(buffers ...[]byte) uint32 {
+	if len(buffers) == 0 {
+		return uint32(C.enet_crc32(nil, 0))
+	}
+
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+
+	cBuffers := make([]C.ENetBuffer, len(buffers))
+	for i, buffer := range buffers {
+		if len(buffer) == 0 {
+			continue
+		}
+		pinner.Pin(&buffer[0])
+		cBuffers[i].data = unsafe.Pointer(&buffer[0])
+		cBuffers[i].dataLength = (C.size_t)(len(buffer))
+	}
+
+	return uint32(C.enet_crc32(&cBuffers[0], (C.size_t)(len(cBuffers))))
+}
+
+func (host *enetHost) EnableCRC32Checksum() {
+	if host.destroyed() {
+		return
+	}
+
+	checksumFuncs.Delete(host.cHost)
+	host.cHost.checksum = (C.ENetChecksumCallback)(C.enet_crc32)
+}
+
+func (host *enetHost) SetChecksum(checksum ChecksumFunc) {
+	if host.destroyed() {
+		return
+	}
+
+	if checksum == nil {
+		checksumFuncs.Delete(host.cHost)
+		host.cHost.checksum = nil
+		return
+	}
+
+	checksumFuncs.Store(host.cHost, checksum)
+	host.cHost.checksum = (C.ENetChecksumCallback)(C.goChecksum)
+}
+
+// withChecksum calls fn, which may send or receive packets on the socket, making sure a
+// Go checksum function can find the host it belongs to.
+func (host *enetHost) withChecksum(fn func()) {
+	if _, ok := checksumFuncs.Load(host.cHost); !ok {
+		fn()
+		return
+	}
+
+	// Stay on the same thread, so the thread-local host is still set when enet calls the
+	// checksum function.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	C.goChecksumSetHost(host.cHost)
+	defer C.goChecksumSetHost(nil)
+
+	fn()
+}
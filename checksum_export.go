@@ -0,0 +1,26 @@
+package enet
+
+// #include <enet/enet.h>
+// ENetHost * goChecksumHost(void);
+import "C"
+import (
+	"unsafe"
+)
+
+// goChecksum is called by enet through the checksum callback set up in
+// Host.SetChecksum.
+//
+//export goChecksum
+func goChecksum(cBuffers *C.ENetBuffer, bufferCount C.size_t) C.enet_uint32 {
+	checksum, ok := checksumFuncs.Load(C.goChecksumHost())
+	if !ok {
+		return 0
+	}
+
+	buffers := make([][]byte, bufferCount)
+	for i, buffer := range unsafe.Slice(cBuffers, bufferCount) {
+		buffers[i] = unsafe.Slice((*byte)(buffer.data), buffer.dataLength)
+	}
+
+	return (C.enet_uint32)(checksum.(ChecksumFunc)(buffers))
+}
@@ -0,0 +1,131 @@
+package enet
+
+// #include <enet/enet.h>
+// size_t goCompressorCompress(void *, const ENetBuffer *, size_t, size_t, enet_uint8 *, size_t);
+// size_t goCompressorDecompress(void *, const enet_uint8 *, size_t, enet_uint8 *, size_t);
+// void goCompressorDestroy(void *);
+import "C"
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// Compressor compresses packets before they are sent on the socket, and decompresses
+// them when they are received. Both ends of a connection must use the same compressor.
+//
+// The slices passed to a Compressor point into memory owned by enet, and must not be
+// retained after the method returns. A Compressor is only called from the goroutine
+// servicing its host, so it should not be shared between hosts.
+type Compressor interface {
+	// Compress compresses the concatenation of the in buffers, which contain limit bytes
+	// in total, into out. Returns the number of bytes written to out, or 0 if the data
+	// could not be compressed into len(out) bytes.
+	Compress(in [][]byte, limit int, out []byte) int
+
+	// Decompress decompresses in into out. Returns the number of bytes written to out,
+	// or 0 if the data could not be decompressed into len(out) bytes.
+	Decompress(in []byte, out []byte) int
+}
+
+func (host *enetHost) SetCompressor(compressor Compressor) {
+	if host.destroyed() {
+		return
+	}
+
+	if compressor == nil {
+		C.enet_host_compress(host.cHost, nil)
+		return
+	}
+
+	// The context is released by goCompressorDestroy, which enet calls when the
+	// compressor is replaced or the host is destroyed.
+	context := C.malloc(C.size_t(unsafe.Sizeof(cgo.Handle(0))))
+	*(*cgo.Handle)(context) = cgo.NewHandle(compressor)
+
+	cCompressor := C.ENetCompressor{
+		context:    context,
+		compress:   (*[0]byte)(C.goCompressorCompress),
+		decompress: (*[0]byte)(C.goCompressorDecompress),
+		destroy:    (*[0]byte)(C.goCompressorDestroy),
+	}
+	C.enet_host_compress(host.cHost, &cCompressor)
+}
+
+type flateCompressor struct {
+	dict []byte
+
+	buffer bytes.Buffer
+	writer *flate.Writer
+	reader io.ReadCloser
+}
+
+// NewFlateCompressor creates a compressor using DEFLATE with the given compression level,
+// from flate.BestSpeed to flate.BestCompression.
+func NewFlateCompressor(level int) (Compressor, error) {
+	return NewFlateDictCompressor(level, nil)
+}
+
+// NewFlateDictCompressor creates a compressor using DEFLATE with a preset dictionary. The
+// dictionary should contain data that is representative of the traffic, such as a typical
+// snapshot, which greatly improves compression of small packets. Both ends of a connection
+// must use the same dictionary.
+func NewFlateDictCompressor(level int, dict []byte) (Compressor, error) {
+	writer, err := flate.NewWriterDict(nil, level, dict)
+	if err != nil {
+		return nil, err
+	}
+
+	return &flateCompressor{
+		dict:   append([]byte(nil), dict...),
+		writer: writer,
+		reader: flate.NewReaderDict(nil, dict),
+	}, nil
+}
+
+func (compressor *flateCompressor) Compress(in [][]byte, limit int, out []byte) int {
+	compressor.buffer.Reset()
+	compressor.writer.Reset(&compressor.buffer)
+
+	for _, buffer := range in {
+		if _, err := compressor.writer.Write(buffer); err != nil {
+			return 0
+		}
+	}
+
+	if err := compressor.writer.Close(); err != nil {
+		return 0
+	}
+
+	if compressor.buffer.Len() > len(out) {
+		return 0
+	}
+
+	return copy(out, compressor.buffer.Bytes())
+}
+
+func (compressor *flateCompressor) Decompress(in []byte, out []byte) int {
+	err := compressor.reader.(flate.Resetter).Reset(bytes.NewReader(in), compressor.dict)
+	if err != nil {
+		return 0
+	}
+
+	n, err := io.ReadFull(compressor.reader, out)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return n
+	}
+	if err != nil {
+		return 0
+	}
+
+	// The output buffer is full, so make sure there's no more data left.
+	var extra [1]byte
+	if _, err := compressor.reader.Read(extra[:]); err != io.EOF {
+		return 0
+	}
+
+	return n
+}
@@ -0,0 +1,41 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+import (
+	"runtime/cgo"
+	"unsafe"
+)
+
+// These functions are called by enet through the ENetCompressor set up in
+// Host.SetCompressor.
+
+func compressorFromContext(context unsafe.Pointer) Compressor {
+	return (*(*cgo.Handle)(context)).Value().(Compressor)
+}
+
+//export goCompressorCompress
+func goCompressorCompress(context unsafe.Pointer, inBuffers *C.ENetBuffer, inBufferCount C.size_t, inLimit C.size_t, outData *C.enet_uint8, outLimit C.size_t) C.size_t {
+	buffers := unsafe.Slice(inBuffers, inBufferCount)
+
+	in := make([][]byte, len(buffers))
+	for i, buffer := range buffers {
+		in[i] = unsafe.Slice((*byte)(buffer.data), buffer.dataLength)
+	}
+
+	out := unsafe.Slice((*byte)(outData), outLimit)
+	return (C.size_t)(compressorFromContext(context).Compress(in, int(inLimit), out))
+}
+
+//export goCompressorDecompress
+func goCompressorDecompress(context unsafe.Pointer, inData *C.enet_uint8, inLimit C.size_t, outData *C.enet_uint8, outLimit C.size_t) C.size_t {
+	in := unsafe.Slice((*byte)(inData), inLimit)
+	out := unsafe.Slice((*byte)(outData), outLimit)
+	return (C.size_t)(compressorFromContext(context).Decompress(in, out))
+}
+
+//export goCompressorDestroy
+func goCompressorDestroy(context unsafe.Pointer) {
+	(*(*cgo.Handle)(context)).Delete()
+	C.free(context)
+}
@@ -0,0 +1,64 @@
+//go:build enetdebug
+
+package enet
+
+// #include <enet/enet.h>
+import "C"
+import (
+	"log"
+	"runtime"
+	"sync"
+)
+
+// When built with the enetdebug tag, packets received through EventReceive are tracked
+// until they are destroyed or sent on. Any packets that are still alive when the host that received
+// them is destroyed are reported as leaks. Hosts, and packets created with NewPacket, are
+// also reported if they are garbage collected without being destroyed or sent.
+
+var debugPackets = struct {
+	sync.Mutex
+	received map[*C.struct__ENetPacket]*C.struct__ENetHost
+}{
+	received: make(map[*C.struct__ENetPacket]*C.struct__ENetHost),
+}
+
+func debugTrackPacket(host *C.struct__ENetHost, packet *C.struct__ENetPacket) {
+	debugPackets.Lock()
+	debugPackets.received[packet] = host
+	debugPackets.Unlock()
+}
+
+func debugUntrackPacket(packet *C.struct__ENetPacket) {
+	debugPackets.Lock()
+	delete(debugPackets.received, packet)
+	debugPackets.Unlock()
+}
+
+func debugReportPackets(host *C.struct__ENetHost) {
+	debugPackets.Lock()
+	defer debugPackets.Unlock()
+
+	for packet, receivedBy := range debugPackets.received {
+		if receivedBy != host {
+			continue
+		}
+		log.Printf("enet: received packet %p of %d bytes was never destroyed", packet, packet.dataLength)
+		delete(debugPackets.received, packet)
+	}
+}
+
+func debugTrackHost(host *enetHost) {
+	runtime.SetFinalizer(host, func(host *enetHost) {
+		if !host.destroyed() {
+			log.Printf("enet: host %p was garbage collected without being destroyed", host.cHost)
+		}
+	})
+}
+
+func debugTrackNewPacket(packet *enetPacket) {
+	runtime.SetFinalizer(packet, func(packet *enetPacket) {
+		if !packet.destroyed() && !packet.sent {
+			log.Printf("enet: packet %p of %d bytes was garbage collected without being sent or destroyed", packet.cPacket, packet.cPacket.dataLength)
+		}
+	})
+}
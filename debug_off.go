@@ -0,0 +1,16 @@
+//go:build !enetdebug
+
+package enet
+
+// #include <enet/enet.h>
+import "C"
+
+func debugTrackPacket(host *C.struct__ENetHost, packet *C.struct__ENetPacket) {}
+
+func debugUntrackPacket(packet *C.struct__ENetPacket) {}
+
+func debugReportPackets(host *C.struct__ENetHost) {}
+
+func debugTrackHost(host *enetHost) {}
+
+func debugTrackNewPacket(packet *enetPacket) {}
@@ -5,16 +5,106 @@ package enet
 // #cgo windows LDFLAGS: -Lenet/ -lenet -lws2_32 -lwinmm
 // #include <enet/enet.h>
 import "C"
-import "fmt"
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
 
-// Initialize enet
-func Initialize() {
-	C.enet_initialize()
+// Functions enet calls back into Go are declared in the preamble of the file that sets
+// them up, and exported from a separate file named after it with an _export suffix. cgo
+// copies the preamble of files with exported functions into the header it generates for
+// them, so that preamble may not contain definitions, and the prototypes enet expects
+// would conflict with the ones cgo generates.
+
+// library keeps track of who is using enet, so independent users of this package don't
+// deinitialize enet while others still need it.
+var library struct {
+	sync.Mutex
+
+	// initialized is true while enet is initialized.
+	initialized bool
+
+	// references is the number of Initialize calls without a matching Deinitialize.
+	references int
+
+	// hosts is the number of hosts that have not been destroyed yet.
+	hosts int
+
+	// hooked is true once enet allocates memory through the hooks of
+	// InitializeWithOptions, which it keeps doing for the rest of the process.
+	hooked atomic.Bool
+
+	// unhooked is true once enet may have allocated memory without the hooks, after
+	// which the hooks can't be installed anymore.
+	unhooked atomic.Bool
 }
 
-// Deinitialize enet
-func Deinitialize() {
-	C.enet_deinitialize()
+// Initialize enet. Initialize may be called multiple times, as long as every call is
+// matched by a call to Deinitialize.
+func Initialize() error {
+	library.Lock()
+	defer library.Unlock()
+
+	if !library.initialized {
+		if C.enet_initialize() < 0 {
+			return ErrInitializeFailed
+		}
+		library.initialized = true
+		noteAllocation()
+	}
+
+	library.references++
+	return nil
+}
+
+// Deinitialize enet. This only deinitializes enet once it has been called as many times
+// as Initialize. If hosts are still alive at that point, enet is deinitialized once the
+// last host is destroyed.
+func Deinitialize() error {
+	library.Lock()
+	defer library.Unlock()
+
+	if library.references == 0 {
+		return ErrNotInitialized
+	}
+
+	library.references--
+	deinitializeIfUnused()
+	return nil
+}
+
+// deinitializeIfUnused deinitializes enet if nobody is using it anymore. The library
+// mutex must be held.
+func deinitializeIfUnused() {
+	if library.initialized && library.references == 0 && library.hosts == 0 {
+		C.enet_deinitialize()
+		library.initialized = false
+		resetMemoryOptions()
+	}
+}
+
+// acquireHost must be called before creating a host, and returns an error if enet is
+// not initialized. If the host can't be created, releaseHost must be called.
+func acquireHost() error {
+	library.Lock()
+	defer library.Unlock()
+
+	if library.references == 0 {
+		return ErrNotInitialized
+	}
+
+	library.hosts++
+	return nil
+}
+
+// releaseHost must be called after destroying a host.
+func releaseHost() {
+	library.Lock()
+	defer library.Unlock()
+
+	library.hosts--
+	deinitializeIfUnused()
 }
 
 // LinkedVersion returns the linked version of enet currently being used.
@@ -0,0 +1,61 @@
+package enet
+
+import (
+	"errors"
+)
+
+var (
+	// ErrInitializeFailed is returned when enet could not be initialized
+	ErrInitializeFailed = errors.New("unable to initialize enet")
+
+	// ErrNotInitialized is returned when creating a host before enet has been initialized,
+	// or when calling Deinitialize more often than Initialize
+	ErrNotInitialized = errors.New("enet is not initialized")
+
+	// ErrAlreadyInitialized is returned by InitializeWithOptions when enet has already
+	// been initialized
+	ErrAlreadyInitialized = errors.New("enet is already initialized")
+
+	// ErrAllocatedWithoutHooks is returned by InitializeWithOptions when enet may already
+	// have allocated memory without its memory hooks
+	ErrAllocatedWithoutHooks = errors.New("enet has already allocated memory without memory hooks")
+
+	// ErrServiceFailed is returned when servicing a host failed, for example because its
+	// socket could not be read from or written to
+	ErrServiceFailed = errors.New("unable to service host")
+
+	// ErrPeerNotConnected is returned when sending to a peer that is not connected
+	ErrPeerNotConnected = errors.New("peer is not connected")
+
+	// ErrInvalidChannel is returned when sending on a channel the peer has not allocated
+	ErrInvalidChannel = errors.New("channel is not allocated for peer")
+
+	// ErrPacketTooLarge is returned when sending a packet that exceeds the maximum packet
+	// size of the host
+	ErrPacketTooLarge = errors.New("packet exceeds maximum packet size")
+
+	// ErrSendFailed is returned when enet could not queue a packet for sending for any
+	// other reason
+	ErrSendFailed = errors.New("unable to send packet")
+
+	// ErrHostDestroyed is returned when using a host that has been destroyed
+	ErrHostDestroyed = errors.New("host has been destroyed")
+
+	// ErrPacketDestroyed is returned when using a packet that has been destroyed
+	ErrPacketDestroyed = errors.New("packet has been destroyed")
+
+	// ErrPacketSent is returned when using a packet other than by sending it again after
+	// it has been sent, since enet owns the packet from then on and destroys it once it
+	// has been sent to all peers
+	ErrPacketSent = errors.New("packet has been sent")
+
+	// ErrNoPeersConnected is returned when broadcasting on a host with no connected peers
+	ErrNoPeersConnected = errors.New("no peers connected to host")
+
+	// ErrResolveFailed is returned when the host of an address could not be resolved
+	ErrResolveFailed = errors.New("unable to resolve host")
+
+	// ErrInvalidAddress is returned when an address can't be used by enet, for example
+	// because it's not an IPv4 address
+	ErrInvalidAddress = errors.New("invalid enet address")
+)
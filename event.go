@@ -39,6 +39,8 @@ type Event interface {
 
 type enetEvent struct {
 	cEvent C.struct__ENetEvent
+	peer   *enetPeer
+	packet *enetPacket
 }
 
 func (event *enetEvent) GetType() EventType {
@@ -46,9 +48,10 @@ func (event *enetEvent) GetType() EventType {
 }
 
 func (event *enetEvent) GetPeer() Peer {
-	return enetPeer{
-		cPeer: event.cEvent.peer,
+	if event.peer == nil {
+		return nil
 	}
+	return event.peer
 }
 
 func (event *enetEvent) GetChannelID() uint8 {
@@ -60,7 +63,8 @@ func (event *enetEvent) GetData() uint32 {
 }
 
 func (event *enetEvent) GetPacket() Packet {
-	return enetPacket{
-		cPacket: event.cEvent.packet,
+	if event.packet == nil {
+		return nil
 	}
+	return event.packet
 }
@@ -0,0 +1,52 @@
+package enet
+
+/*
+#include <enet/enet.h>
+
+void goPacketFree(ENetPacket *);
+*/
+import "C"
+import (
+	"runtime/cgo"
+	"unsafe"
+)
+
+// packetFree holds the Go functions to call when enet frees a packet.
+type packetFree struct {
+	// callback is set through Packet.SetFreeCallback.
+	callback func()
+
+	// release returns the data of a packet created by a BufferPool to the pool.
+	release func()
+
+	// packet is the handle of a packet that has been sent, which is marked as destroyed
+	// once enet frees it.
+	packet *enetPacket
+}
+
+// freeState returns the functions to call when the packet is freed, setting up the
+// free callback of the packet the first time. The state is kept in the userData of the
+// packet, and released by goPacketFree.
+func (packet *enetPacket) freeState() *packetFree {
+	if packet.cPacket.userData != nil {
+		return (*(*cgo.Handle)(packet.cPacket.userData)).Value().(*packetFree)
+	}
+
+	state := &packetFree{}
+
+	userData := C.malloc(C.size_t(unsafe.Sizeof(cgo.Handle(0))))
+	*(*cgo.Handle)(userData) = cgo.NewHandle(state)
+
+	packet.cPacket.userData = userData
+	packet.cPacket.freeCallback = (C.ENetPacketFreeCallback)(C.goPacketFree)
+	return state
+}
+
+func (packet *enetPacket) SetFreeCallback(callback func()) error {
+	if err := packet.usable(); err != nil {
+		return err
+	}
+
+	packet.freeState().callback = callback
+	return nil
+}
@@ -0,0 +1,30 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+import (
+	"runtime/cgo"
+)
+
+// goPacketFree is called by enet when a packet with a free callback set up by
+// enetPacket.freeState is destroyed.
+//
+//export goPacketFree
+func goPacketFree(cPacket *C.ENetPacket) {
+	handle := (*cgo.Handle)(cPacket.userData)
+	state := handle.Value().(*packetFree)
+
+	handle.Delete()
+	C.free(cPacket.userData)
+	cPacket.userData = nil
+
+	if state.packet != nil {
+		state.packet.cPacket = nil
+	}
+	if state.callback != nil {
+		state.callback()
+	}
+	if state.release != nil {
+		state.release()
+	}
+}
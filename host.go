@@ -3,44 +3,415 @@ package enet
 // #include <enet/enet.h>
 import "C"
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
 )
 
 // Host for communicating with peers
 type Host interface {
-	Destroy()
-	Service(timeout uint32) Event
+	// Destroy destroys the host. Returns ErrHostDestroyed if the host has already been
+	// destroyed, in which case nothing happens. All other methods that return an error
+	// return ErrHostDestroyed after this, and the others do nothing.
+	Destroy() error
 
-	Connect(addr Address, channelCount int, data uint32) (Peer, error)
+	// Service waits up to timeout milliseconds for an event, sending and receiving on the
+	// socket. Returns an event of type EventNone if no event occurred within the timeout,
+	// or ErrServiceFailed if the host could not be serviced.
+	Service(timeout uint32) (Event, error)
+
+	// CheckEvents returns the next queued event without sending or receiving on the
+	// socket. Returns an event of type EventNone if no events are queued.
+	CheckEvents() (Event, error)
+
+	// ServiceAll calls Service with the given timeout, followed by CheckEvents until no
+	// more events are queued, and returns all events that occurred. Returns an empty slice
+	// if no event occurred within the timeout. The peers of all returned events stay
+	// usable until the next call to Service, CheckEvents or ServiceAll.
+	ServiceAll(timeout uint32) ([]Event, error)
+
+	// Run services the host until the context is cancelled, calling handler for every
+	// event. When the context is cancelled, any queued packets are flushed and the host
+	// is destroyed. Returns nil when the context is cancelled, or the error if servicing
+	// the host failed, in which case the host is destroyed as well.
+	Run(ctx context.Context, handler EventHandler) error
+
+	// Events runs the host like Run in a new goroutine, and returns a stream of its
+	// events. The host is not serviced while an event from the stream is being handled,
+	// so the host and its peers may be used until the next call to EventStream.Next.
+	// Cancelling the context destroys the host in the background, so the last event
+	// must not be used anymore after that.
+	Events(ctx context.Context) *EventStream
+
+	// SetReceiveQueueing enables or disables queueing received packets per peer. While
+	// enabled, Service and CheckEvents don't return receive events, but queue the packets
+	// on their peer to be taken with Peer.Receive, which may then be called from any
+	// goroutine. Service may then return an event of type EventNone before its timeout,
+	// if only packets were received. This should be set before the host is serviced.
+	SetReceiveQueueing(enabled bool)
+
+	// Peers returns all connected peers, including peers that will disconnect once all
+	// queued packets have been sent.
+	Peers() []Peer
+
+	// AllPeers returns every peer allocated by this host, including disconnected ones.
+	AllPeers() []PeerSlot
+
+	// ConnectedPeerCount returns the number of peers returned by Peers.
+	ConnectedPeerCount() int
+
+	// SetStateChangeCallback sets a function that is called when the state of a peer
+	// changes. States are compared after every call to Service and CheckEvents, so states
+	// that only last during a single call are not reported. The states of the peers when
+	// the callback is set are the starting point, so existing connections are only
+	// reported once their state changes. A nil function removes the callback.
+	SetStateChangeCallback(callback StateChangeFunc)
+
+	// Stats returns the traffic counters of this host, accumulated since the host was
+	// created or since the last call to ResetStats.
+	Stats() HostStats
+
+	// ResetStats returns the same snapshot as Stats and resets the traffic counters to 0.
+	ResetStats() HostStats
+
+	// Connect queues a connection to a foreign host. The address can be an Address or a
+	// standard library address such as *net.UDPAddr. Use net.UDPAddrFromAddrPort or
+	// AddressFromAddrPort to connect to a netip.AddrPort.
+	Connect(addr net.Addr, channelCount int, data uint32) (Peer, error)
+
+	// SetBandwidthLimit adjusts the incoming and outgoing bandwidth of this host in bytes
+	// per second. A value of 0 means unlimited bandwidth.
+	SetBandwidthLimit(incomingBandwidth, outgoingBandwidth uint32)
+
+	// SetChannelLimit limits the maximum allowed channels of future incoming connections.
+	// A value of 0 means the maximum number of channels the protocol allows.
+	SetChannelLimit(channelLimit uint64)
+
+	// Flush sends any queued packets to their peers immediately, without waiting for the
+	// next call to Service.
+	Flush()
+
+	// DuplicatePeers returns the number of peers that may connect from the same IP address.
+	DuplicatePeers() uint64
+
+	// SetDuplicatePeers sets the number of peers that may connect from the same IP
+	// address, between 1 and MaximumPeerCount.
+	SetDuplicatePeers(duplicatePeers uint64) error
+
+	// MaximumPacketSize returns the size limit of packets sent or received by this host.
+	MaximumPacketSize() uint64
+
+	// SetMaximumPacketSize sets the size limit of packets sent or received by this host.
+	SetMaximumPacketSize(size uint64) error
+
+	// MaximumWaitingData returns the limit on the amount of data a peer may have waiting
+	// to be delivered.
+	MaximumWaitingData() uint64
+
+	// SetMaximumWaitingData sets the limit on the amount of data a peer may have waiting
+	// to be delivered.
+	SetMaximumWaitingData(size uint64) error
+
+	// MTU returns the maximum transmission unit used for new connections.
+	MTU() uint32
+
+	// SetMTU sets the maximum transmission unit used for new connections, between
+	// MinimumMTU and MaximumMTU. Existing connections keep the MTU they negotiated.
+	SetMTU(mtu uint32) error
 
 	CompressWithRangeCoder() error
+
+	// EnableCRC32Checksum makes this host add a CRC32 checksum to every packet it sends,
+	// and drop received packets with an invalid checksum. Both ends of a connection must
+	// use the same checksum.
+	EnableCRC32Checksum()
+
+	// SetChecksum makes this host use a custom checksum function for every packet it sends
+	// and receives, replacing any previous checksum. A nil function disables checksums.
+	// Both ends of a connection must use the same checksum.
+	SetChecksum(checksum ChecksumFunc)
+
+	// SetInterceptor sets a function that is called for every raw UDP datagram received on
+	// the socket of this host, before enet parses it. This allows answering out-of-band
+	// queries on the same port. A nil function removes the interceptor.
+	SetInterceptor(interceptor InterceptFunc)
+
+	// SendRaw sends a raw UDP datagram to the given address on the socket of this host,
+	// bypassing the enet protocol. This can be used to reply to intercepted datagrams.
+	SendRaw(addr net.Addr, data []byte) error
+
+	// SetCompressor sets the packet compressor of this host, replacing any previous
+	// compressor. A nil compressor disables compression.
+	SetCompressor(compressor Compressor)
 	BroadcastBytes(data []byte, channel uint8, flags PacketFlags) error
+
+	// BroadcastPacket queues a packet to be sent to all connected peers. Enet takes
+	// ownership of the packet, and destroys it immediately if no peer takes it. In that
+	// case, it returns ErrNoPeersConnected if no peer is connected, or the reason a
+	// connected peer rejected the packet, such as ErrPacketTooLarge. If the host has been
+	// destroyed, ErrHostDestroyed is returned and the caller keeps ownership of the
+	// packet.
 	BroadcastPacket(packet Packet, channel uint8) error
 	BroadcastString(str string, channel uint8, flags PacketFlags) error
+
+	// Multicast queues a packet to be sent to the given peers, sharing a single packet
+	// between them. Peers that are listed more than once only get the packet once. Peers
+	// that are not connected to this host or can't take the packet are skipped. Ownership of the packet is handled the same way as in BroadcastPacket.
+	Multicast(packet Packet, channel uint8, peers []Peer) error
+
+	// BroadcastExcept queues a packet to be sent to all connected peers except the
+	// excluded ones. Ownership of the packet is handled the same way as in
+	// BroadcastPacket.
+	BroadcastExcept(packet Packet, channel uint8, exclude ...Peer) error
 }
 
 type enetHost struct {
 	cHost *C.struct__ENetHost
+	stats HostStats
+
+	// peers maps the enet peers of ongoing connections to their Go handle.
+	peers map[*C.struct__ENetPeer]*enetPeer
+
+	// queueReceives is true if received packets are queued on their peer instead of
+	// being returned in receive events.
+	queueReceives atomic.Bool
+
+	// stateChange is called when the state of a peer changes, with the states of all
+	// peers as seen last in observedPeers.
+	stateChange   StateChangeFunc
+	observedPeers []observedPeer
+
+	// disconnected are peers that have been returned in a disconnect event, which are
+	// released on the next call to Service, CheckEvents or ServiceAll.
+	disconnected []*enetPeer
+}
+
+// destroyed returns true if the host has been destroyed.
+func (host *enetHost) destroyed() bool {
+	return host.cHost == nil
 }
 
-func (host *enetHost) Destroy() {
+func (host *enetHost) Destroy() error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+
+	host.releaseDisconnectedPeers()
+	for _, peer := range host.peers {
+		host.releasePeer(peer)
+	}
+
+	debugReportPackets(host.cHost)
+	checksumFuncs.Delete(host.cHost)
+	interceptFuncs.Delete(host.cHost)
 	C.enet_host_destroy(host.cHost)
+	host.cHost = nil
+	releaseHost()
+	return nil
+}
+
+// peerFor returns the Go handle for an enet peer, creating it if this is a new connection.
+func (host *enetHost) peerFor(cPeer *C.struct__ENetPeer) *enetPeer {
+	if cPeer == nil {
+		return nil
+	}
+
+	peer, ok := host.peers[cPeer]
+	if ok && peer.connectID != cPeer.connectID {
+		// The enet peer has been reused for a new connection without us noticing.
+		host.releasePeer(peer)
+		ok = false
+	}
+
+	if !ok {
+		peer = &enetPeer{
+			cPeer:     cPeer,
+			host:      host,
+			connectID: cPeer.connectID,
+		}
+		host.peers[cPeer] = peer
+	}
+	return peer
+}
+
+// releasePeer releases a peer immediately.
+func (host *enetHost) releasePeer(peer *enetPeer) {
+	if host.peers[peer.cPeer] == peer {
+		delete(host.peers, peer.cPeer)
+	}
+	peer.release()
+}
+
+// releaseDisconnectedPeers releases the peers of previous disconnect events. This must
+// happen before enet may reuse the underlying peers for a new connection, or the peers
+// must be detached first, like detachDisconnectedPeer does for Connect.
+func (host *enetHost) releaseDisconnectedPeers() {
+	for _, peer := range host.disconnected {
+		peer.release()
+	}
+	host.disconnected = nil
 }
 
-func (host *enetHost) Service(timeout uint32) Event {
+// detachDisconnectedPeer detaches the peers of previous disconnect events from the enet
+// peer the next call to enet_host_connect will use, which is the first disconnected one.
+func (host *enetHost) detachDisconnectedPeer() {
+	if len(host.disconnected) == 0 {
+		return
+	}
+
+	cPeers := host.cPeers()
+	for i := range cPeers {
+		if cPeers[i].state != C.ENET_PEER_STATE_DISCONNECTED {
+			continue
+		}
+
+		for _, peer := range host.disconnected {
+			if peer.cPeer == &cPeers[i] {
+				peer.detach()
+			}
+		}
+		return
+	}
+}
+
+func (host *enetHost) Service(timeout uint32) (Event, error) {
+	if host.destroyed() {
+		return nil, ErrHostDestroyed
+	}
+
+	host.releaseDisconnectedPeers()
+	return host.service(timeout)
+}
+
+// service services the host like Service, but keeps the peers of previous disconnect
+// events, so ServiceAll can return several events without releasing any of their peers.
+func (host *enetHost) service(timeout uint32) (Event, error) {
 	ret := &enetEvent{}
-	C.enet_host_service(
-		host.cHost,
-		&ret.cEvent,
-		(C.enet_uint32)(timeout),
-	)
-	return ret
+	var status C.int
+	host.withChecksum(func() {
+		status = C.enet_host_service(
+			host.cHost,
+			&ret.cEvent,
+			(C.enet_uint32)(timeout),
+		)
+	})
+	host.collectStats()
+
+	if status < 0 {
+		return nil, ErrServiceFailed
+	}
+
+	host.trackEvent(ret)
+	host.checkStateChanges()
+	if host.queueReceive(ret) {
+		// Return the next event that isn't queued instead, if there is one.
+		return host.checkEvents()
+	}
+
+	return ret, nil
+}
+
+func (host *enetHost) CheckEvents() (Event, error) {
+	if host.destroyed() {
+		return nil, ErrHostDestroyed
+	}
+
+	host.releaseDisconnectedPeers()
+	return host.checkEvents()
+}
+
+// checkEvents checks for events like CheckEvents, but keeps the peers of previous
+// disconnect events like service.
+func (host *enetHost) checkEvents() (Event, error) {
+	for {
+		ret := &enetEvent{}
+		status := C.enet_host_check_events(
+			host.cHost,
+			&ret.cEvent,
+		)
+
+		if status < 0 {
+			return nil, ErrServiceFailed
+		}
+
+		host.trackEvent(ret)
+		host.checkStateChanges()
+		if !host.queueReceive(ret) {
+			return ret, nil
+		}
+	}
 }
 
-func (host *enetHost) Connect(addr Address, channelCount int, data uint32) (Peer, error) {
+// trackEvent is called for every event returned by the host.
+func (host *enetHost) trackEvent(ev *enetEvent) {
+	switch ev.cEvent._type {
+	case C.ENET_EVENT_TYPE_CONNECT:
+		ev.peer = host.peerFor(ev.cEvent.peer)
+
+	case C.ENET_EVENT_TYPE_DISCONNECT:
+		// Enet resets the peer before returning the event, which clears its connect ID,
+		// so the handle is looked up without checking it. Keep the peer usable while the
+		// disconnect event is handled, but make sure the next connection using the same
+		// enet peer gets a new handle.
+		peer, ok := host.peers[ev.cEvent.peer]
+		if !ok {
+			peer = host.peerFor(ev.cEvent.peer)
+		}
+		ev.peer = peer
+		delete(host.peers, ev.cEvent.peer)
+		host.disconnected = append(host.disconnected, peer)
+
+	case C.ENET_EVENT_TYPE_RECEIVE:
+		ev.peer = host.peerFor(ev.cEvent.peer)
+		ev.packet = &enetPacket{
+			cPacket: ev.cEvent.packet,
+		}
+		debugTrackPacket(host.cHost, ev.cEvent.packet)
+	}
+}
+
+func (host *enetHost) ServiceAll(timeout uint32) ([]Event, error) {
+	if host.destroyed() {
+		return nil, ErrHostDestroyed
+	}
+
+	// The peers of disconnect events in the returned events must stay usable until the
+	// next call, so only the peers of previous calls are released.
+	host.releaseDisconnectedPeers()
+
+	var ret []Event
+
+	ev, err := host.service(timeout)
+	for err == nil && ev.GetType() != EventNone {
+		ret = append(ret, ev)
+		ev, err = host.checkEvents()
+	}
+
+	return ret, err
+}
+
+func (host *enetHost) Connect(addr net.Addr, channelCount int, data uint32) (Peer, error) {
+	if host.destroyed() {
+		return nil, ErrHostDestroyed
+	}
+
+	cAddr, err := cAddressFor(addr)
+	if err != nil {
+		return nil, err
+	}
+	if cAddr == nil {
+		return nil, fmt.Errorf("%w: no address to connect to", ErrInvalidAddress)
+	}
+
+	// Connecting while a disconnect event is handled may reuse the enet peer of that
+	// event, which must not keep pointing at the new connection.
+	host.detachDisconnectedPeer()
+
 	peer := C.enet_host_connect(
 		host.cHost,
-		&(addr.(*enetAddress)).cAddr,
+		cAddr,
 		(C.size_t)(channelCount),
 		(C.enet_uint32)(data),
 	)
@@ -49,12 +420,48 @@ func (host *enetHost) Connect(addr Address, channelCount int, data uint32) (Peer
 		return nil, errors.New("couldn't connect to foreign peer")
 	}
 
-	return enetPeer{
-		cPeer: peer,
-	}, nil
+	return host.peerFor(peer), nil
+}
+
+func (host *enetHost) SetBandwidthLimit(incomingBandwidth, outgoingBandwidth uint32) {
+	if host.destroyed() {
+		return
+	}
+
+	C.enet_host_bandwidth_limit(
+		host.cHost,
+		(C.enet_uint32)(incomingBandwidth),
+		(C.enet_uint32)(outgoingBandwidth),
+	)
+}
+
+func (host *enetHost) SetChannelLimit(channelLimit uint64) {
+	if host.destroyed() {
+		return
+	}
+
+	C.enet_host_channel_limit(
+		host.cHost,
+		(C.size_t)(channelLimit),
+	)
+}
+
+func (host *enetHost) Flush() {
+	if host.destroyed() {
+		return
+	}
+
+	host.withChecksum(func() {
+		C.enet_host_flush(host.cHost)
+	})
+	host.collectStats()
 }
 
 func (host *enetHost) CompressWithRangeCoder() error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+
 	status := C.enet_host_compress_with_range_coder(host.cHost)
 
 	if status == -1 {
@@ -66,31 +473,28 @@ func (host *enetHost) CompressWithRangeCoder() error {
 	return nil
 }
 
-// NewHost creats a host for communicating to peers
-func NewHost(addr Address, peerCount, channelLimit uint64, incomingBandwidth, outgoingBandwidth uint32) (Host, error) {
-	var cAddr *C.struct__ENetAddress
-	if addr != nil {
-		cAddr = &(addr.(*enetAddress)).cAddr
-	}
-
-	host := C.enet_host_create(
-		cAddr,
-		(C.size_t)(peerCount),
-		(C.size_t)(channelLimit),
-		(C.enet_uint32)(incomingBandwidth),
-		(C.enet_uint32)(outgoingBandwidth),
-	)
-
-	if host == nil {
-		return nil, errors.New("unable to create host")
+// NewHost creats a host for communicating to peers. Enet must be initialized first. Use
+// NewHostFromConfig to configure the limits of the host.
+func NewHost(addr net.Addr, peerCount, channelLimit uint64, incomingBandwidth, outgoingBandwidth uint32) (Host, error) {
+	// Enet clamps the channel limit, which HostConfig rejects instead.
+	if channelLimit > MaximumChannelCount {
+		channelLimit = MaximumChannelCount
 	}
 
-	return &enetHost{
-		cHost: host,
-	}, nil
+	return NewHostFromConfig(NewHostConfig(
+		WithAddress(addr),
+		WithPeerCount(peerCount),
+		WithChannelLimit(channelLimit),
+		WithBandwidthLimit(incomingBandwidth, outgoingBandwidth),
+	))
 }
 
 func (host *enetHost) BroadcastBytes(data []byte, channel uint8, flags PacketFlags) error {
+	// Nobody else could destroy the packet if the host can't take it.
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+
 	packet, err := NewPacket(data, flags)
 	if err != nil {
 		return err
@@ -99,18 +503,26 @@ func (host *enetHost) BroadcastBytes(data []byte, channel uint8, flags PacketFla
 }
 
 func (host *enetHost) BroadcastPacket(packet Packet, channel uint8) error {
-	C.enet_host_broadcast(
-		host.cHost,
-		(C.enet_uint8)(channel),
-		packet.(enetPacket).cPacket,
-	)
-	return nil
-}
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
 
-func (host *enetHost) BroadcastString(str string, channel uint8, flags PacketFlags) error {
-	packet, err := NewPacket([]byte(str), flags)
+	p, err := packetFor(packet)
 	if err != nil {
 		return err
 	}
-	return host.BroadcastPacket(packet, channel)
+
+	// This sends to every peer like enet_host_broadcast, but counts the peers that take
+	// the packet, so the handle is only cleared if the packet has been destroyed.
+	var result multicastResult
+	cPeers := host.cPeers()
+	for i := range cPeers {
+		result.add(host.sendTo(p, channel, &cPeers[i]))
+	}
+
+	return host.finishMulticast(p, result)
+}
+
+func (host *enetHost) BroadcastString(str string, channel uint8, flags PacketFlags) error {
+	return host.BroadcastBytes([]byte(str), channel, flags)
 }
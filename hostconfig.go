@@ -0,0 +1,334 @@
+package enet
+
+/*
+#include <enet/enet.h>
+
+// The largest packet enet can send, split into the maximum number of fragments that fit
+// the maximum MTU.
+#define GO_MAXIMUM_PACKET_SIZE_LIMIT ((unsigned long long)ENET_PROTOCOL_MAXIMUM_FRAGMENT_COUNT * \
+	(ENET_PROTOCOL_MAXIMUM_MTU - sizeof(ENetProtocolHeader) - sizeof(ENetProtocolSendFragment)))
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+const (
+	// MinimumMTU is the smallest maximum transmission unit allowed by the enet protocol
+	MinimumMTU = C.ENET_PROTOCOL_MINIMUM_MTU
+
+	// MaximumMTU is the largest maximum transmission unit allowed by the enet protocol
+	MaximumMTU = C.ENET_PROTOCOL_MAXIMUM_MTU
+
+	// MaximumPeerCount is the largest number of peers a host can allocate
+	MaximumPeerCount = C.ENET_PROTOCOL_MAXIMUM_PEER_ID
+
+	// MaximumChannelCount is the largest number of channels a peer can use
+	MaximumChannelCount = C.ENET_PROTOCOL_MAXIMUM_CHANNEL_COUNT
+
+	// MaximumPacketSizeLimit is the largest packet enet can send, limited by the maximum
+	// number of fragments of a packet. It is the upper bound of the maximum packet size
+	// and maximum waiting data of a host.
+	MaximumPacketSizeLimit = C.GO_MAXIMUM_PACKET_SIZE_LIMIT
+
+	// DefaultMTU is the default maximum transmission unit of a host
+	DefaultMTU = C.ENET_HOST_DEFAULT_MTU
+
+	// DefaultMaximumPacketSize is the default size limit of packets sent or received by
+	// a host
+	DefaultMaximumPacketSize = C.ENET_HOST_DEFAULT_MAXIMUM_PACKET_SIZE
+
+	// DefaultMaximumWaitingData is the default limit on the amount of data a peer may
+	// have waiting to be delivered
+	DefaultMaximumWaitingData = C.ENET_HOST_DEFAULT_MAXIMUM_WAITING_DATA
+)
+
+// HostConfig configures a host created with NewHostFromConfig
+type HostConfig struct {
+	// Address is the address to listen on, or nil for a host that only connects to
+	// other hosts. Besides Address, this can be a standard library address such as
+	// *net.UDPAddr.
+	Address net.Addr
+
+	// PeerCount is the number of peers the host can connect to, between 1 and
+	// MaximumPeerCount
+	PeerCount uint64
+
+	// ChannelLimit is the maximum number of channels a peer can use, up to
+	// MaximumChannelCount. 0 means MaximumChannelCount.
+	ChannelLimit uint64
+
+	// IncomingBandwidth is the downstream bandwidth of the host in bytes per second, or
+	// 0 if unlimited
+	IncomingBandwidth uint32
+
+	// OutgoingBandwidth is the upstream bandwidth of the host in bytes per second, or 0
+	// if unlimited
+	OutgoingBandwidth uint32
+
+	// DuplicatePeers is the number of peers that may connect from the same IP address,
+	// between 1 and MaximumPeerCount
+	DuplicatePeers uint64
+
+	// MaximumPacketSize is the size limit of packets sent or received by the host,
+	// between 1 and MaximumPacketSizeLimit. Connections sending larger packets are reset.
+	MaximumPacketSize uint64
+
+	// MaximumWaitingData is the limit on the amount of data a peer may have waiting to
+	// be delivered, for example because packets are waiting on missing fragments, between
+	// 1 and MaximumPacketSizeLimit
+	MaximumWaitingData uint64
+
+	// MTU is the maximum transmission unit used for new connections, between MinimumMTU
+	// and MaximumMTU
+	MTU uint32
+}
+
+// HostOption changes a setting of a HostConfig
+type HostOption func(config *HostConfig)
+
+// WithAddress sets the address the host listens on.
+func WithAddress(addr net.Addr) HostOption {
+	return func(config *HostConfig) {
+		config.Address = addr
+	}
+}
+
+// WithPeerCount sets the number of peers the host can connect to.
+func WithPeerCount(peerCount uint64) HostOption {
+	return func(config *HostConfig) {
+		config.PeerCount = peerCount
+	}
+}
+
+// WithChannelLimit sets the maximum number of channels a peer can use.
+func WithChannelLimit(channelLimit uint64) HostOption {
+	return func(config *HostConfig) {
+		config.ChannelLimit = channelLimit
+	}
+}
+
+// WithBandwidthLimit sets the downstream and upstream bandwidth of the host.
+func WithBandwidthLimit(incomingBandwidth, outgoingBandwidth uint32) HostOption {
+	return func(config *HostConfig) {
+		config.IncomingBandwidth = incomingBandwidth
+		config.OutgoingBandwidth = outgoingBandwidth
+	}
+}
+
+// WithDuplicatePeers sets the number of peers that may connect from the same IP address.
+func WithDuplicatePeers(duplicatePeers uint64) HostOption {
+	return func(config *HostConfig) {
+		config.DuplicatePeers = duplicatePeers
+	}
+}
+
+// WithMaximumPacketSize sets the size limit of packets sent or received by the host.
+func WithMaximumPacketSize(size uint64) HostOption {
+	return func(config *HostConfig) {
+		config.MaximumPacketSize = size
+	}
+}
+
+// WithMaximumWaitingData sets the limit on the amount of data a peer may have waiting to
+// be delivered.
+func WithMaximumWaitingData(size uint64) HostOption {
+	return func(config *HostConfig) {
+		config.MaximumWaitingData = size
+	}
+}
+
+// WithMTU sets the maximum transmission unit used for new connections.
+func WithMTU(mtu uint32) HostOption {
+	return func(config *HostConfig) {
+		config.MTU = mtu
+	}
+}
+
+// NewHostConfig returns the default configuration of a host with a single peer, with the
+// given options applied.
+func NewHostConfig(options ...HostOption) HostConfig {
+	config := HostConfig{
+		PeerCount:          1,
+		DuplicatePeers:     MaximumPeerCount,
+		MaximumPacketSize:  DefaultMaximumPacketSize,
+		MaximumWaitingData: DefaultMaximumWaitingData,
+		MTU:                DefaultMTU,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+// Validate returns an error if a setting is outside of the limits of the enet protocol.
+func (config HostConfig) Validate() error {
+	if config.PeerCount < 1 || config.PeerCount > MaximumPeerCount {
+		return fmt.Errorf("peer count must be between 1 and %d", MaximumPeerCount)
+	}
+	if config.ChannelLimit > MaximumChannelCount {
+		return fmt.Errorf("channel limit must not be greater than %d", MaximumChannelCount)
+	}
+	if err := validateDuplicatePeers(config.DuplicatePeers); err != nil {
+		return err
+	}
+	if err := validateMaximumPacketSize(config.MaximumPacketSize); err != nil {
+		return err
+	}
+	if err := validateMaximumWaitingData(config.MaximumWaitingData); err != nil {
+		return err
+	}
+	return validateMTU(config.MTU)
+}
+
+func validateDuplicatePeers(duplicatePeers uint64) error {
+	if duplicatePeers < 1 || duplicatePeers > MaximumPeerCount {
+		return fmt.Errorf("duplicate peers must be between 1 and %d", MaximumPeerCount)
+	}
+	return nil
+}
+
+func validateMaximumPacketSize(size uint64) error {
+	if size < 1 || size > MaximumPacketSizeLimit {
+		return fmt.Errorf("maximum packet size must be between 1 and %d", uint64(MaximumPacketSizeLimit))
+	}
+	return nil
+}
+
+func validateMaximumWaitingData(size uint64) error {
+	if size < 1 || size > MaximumPacketSizeLimit {
+		return fmt.Errorf("maximum waiting data must be between 1 and %d", uint64(MaximumPacketSizeLimit))
+	}
+	return nil
+}
+
+func validateMTU(mtu uint32) error {
+	if mtu < MinimumMTU || mtu > MaximumMTU {
+		return fmt.Errorf("mtu must be between %d and %d", MinimumMTU, MaximumMTU)
+	}
+	return nil
+}
+
+// NewHostFromConfig creates a host for communicating to peers, configured by the given
+// config. Enet must be initialized first.
+func NewHostFromConfig(config HostConfig) (Host, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	cAddr, err := cAddressFor(config.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := acquireHost(); err != nil {
+		return nil, err
+	}
+
+	host := C.enet_host_create(
+		cAddr,
+		(C.size_t)(config.PeerCount),
+		(C.size_t)(config.ChannelLimit),
+		(C.enet_uint32)(config.IncomingBandwidth),
+		(C.enet_uint32)(config.OutgoingBandwidth),
+	)
+
+	if host == nil {
+		releaseHost()
+		return nil, errors.New("unable to create host")
+	}
+
+	host.duplicatePeers = (C.size_t)(config.DuplicatePeers)
+	host.maximumPacketSize = (C.size_t)(config.MaximumPacketSize)
+	host.maximumWaitingData = (C.size_t)(config.MaximumWaitingData)
+	host.mtu = (C.enet_uint32)(config.MTU)
+
+	ret := &enetHost{
+		cHost: host,
+		peers: make(map[*C.struct__ENetPeer]*enetPeer),
+	}
+	debugTrackHost(ret)
+	return ret, nil
+}
+
+func (host *enetHost) DuplicatePeers() uint64 {
+	if host.destroyed() {
+		return 0
+	}
+
+	return uint64(host.cHost.duplicatePeers)
+}
+
+func (host *enetHost) SetDuplicatePeers(duplicatePeers uint64) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+	if err := validateDuplicatePeers(duplicatePeers); err != nil {
+		return err
+	}
+
+	host.cHost.duplicatePeers = (C.size_t)(duplicatePeers)
+	return nil
+}
+
+func (host *enetHost) MaximumPacketSize() uint64 {
+	if host.destroyed() {
+		return 0
+	}
+
+	return uint64(host.cHost.maximumPacketSize)
+}
+
+func (host *enetHost) SetMaximumPacketSize(size uint64) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+	if err := validateMaximumPacketSize(size); err != nil {
+		return err
+	}
+
+	host.cHost.maximumPacketSize = (C.size_t)(size)
+	return nil
+}
+
+func (host *enetHost) MaximumWaitingData() uint64 {
+	if host.destroyed() {
+		return 0
+	}
+
+	return uint64(host.cHost.maximumWaitingData)
+}
+
+func (host *enetHost) SetMaximumWaitingData(size uint64) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+	if err := validateMaximumWaitingData(size); err != nil {
+		return err
+	}
+
+	host.cHost.maximumWaitingData = (C.size_t)(size)
+	return nil
+}
+
+func (host *enetHost) MTU() uint32 {
+	if host.destroyed() {
+		return 0
+	}
+
+	return uint32(host.cHost.mtu)
+}
+
+func (host *enetHost) SetMTU(mtu uint32) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+	if err := validateMTU(mtu); err != nil {
+		return err
+	}
+
+	host.cHost.mtu = (C.enet_uint32)(mtu)
+	return nil
+}
@@ -0,0 +1,92 @@
+package enet
+
+/*
+#include <enet/enet.h>
+
+int goIntercept(ENetHost *, ENetEvent *);
+*/
+import "C"
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// InterceptResult is returned by an InterceptFunc to tell enet what to do with a datagram
+type InterceptResult int
+
+const (
+	// InterceptIgnore lets enet process the datagram as usual
+	InterceptIgnore InterceptResult = 0
+
+	// InterceptHandled means the datagram has been handled and enet should not process it
+	InterceptHandled InterceptResult = 1
+
+	// InterceptError aborts servicing the host, making Host.Service return ErrServiceFailed
+	InterceptError InterceptResult = -1
+)
+
+// InterceptFunc is called for every raw UDP datagram a host receives, before enet parses
+// it. The data is only valid until the function returns.
+type InterceptFunc func(from Address, data []byte) InterceptResult
+
+// interceptFuncs maps hosts to the Go intercept function they use.
+var interceptFuncs sync.Map
+
+func (host *enetHost) SetInterceptor(interceptor InterceptFunc) {
+	if host.destroyed() {
+		return
+	}
+
+	if interceptor == nil {
+		interceptFuncs.Delete(host.cHost)
+		host.cHost.intercept = nil
+		return
+	}
+
+	interceptFuncs.Store(host.cHost, interceptor)
+	host.cHost.intercept = (C.ENetInterceptCallback)(C.goIntercept)
+}
+
+func (host *enetHost) SendRaw(addr net.Addr, data []byte) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+
+	cAddr, err := cAddressFor(addr)
+	if err != nil {
+		return err
+	}
+	if cAddr == nil {
+		return fmt.Errorf("%w: no address to send to", ErrInvalidAddress)
+	}
+
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+
+	var buffer C.ENetBuffer
+	if len(data) > 0 {
+		pinner.Pin(&data[0])
+		buffer.data = unsafe.Pointer(&data[0])
+		buffer.dataLength = (C.size_t)(len(data))
+	}
+
+	sent := C.enet_socket_send(
+		host.cHost.socket,
+		cAddr,
+		&buffer,
+		1,
+	)
+
+	if sent < 0 {
+		return ErrSendFailed
+	}
+	if int(sent) != len(data) {
+		// The socket would have blocked, so the datagram was dropped.
+		return ErrSendFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// goIntercept is called by enet through the intercept callback set up in
+// Host.SetInterceptor.
+//
+//export goIntercept
+func goIntercept(cHost *C.ENetHost, cEvent *C.ENetEvent) C.int {
+	interceptor, ok := interceptFuncs.Load(cHost)
+	if !ok {
+		return 0
+	}
+
+	from := &enetAddress{
+		cAddr: cHost.receivedAddress,
+	}
+	data := unsafe.Slice((*byte)(cHost.receivedData), cHost.receivedDataLength)
+
+	return (C.int)(interceptor.(InterceptFunc)(from, data))
+}
@@ -0,0 +1,156 @@
+package enet
+
+/*
+#include <enet/enet.h>
+
+// Every allocation is prefixed with a header holding its size, so it can be subtracted
+// from the live byte count when it's freed. 16 bytes keeps the memory suitably aligned.
+#define GO_MEMORY_HEADER 16
+
+static size_t goMemoryLimit;
+static size_t goMemoryBytes;
+static size_t goMemoryAllocations;
+
+void goNoMemory(void);
+
+static void * goMemoryMalloc(size_t size) {
+	size_t previous = __atomic_fetch_add(&goMemoryBytes, size, __ATOMIC_RELAXED);
+	size_t limit = __atomic_load_n(&goMemoryLimit, __ATOMIC_RELAXED);
+	if (limit != 0 && previous + size > limit) {
+		__atomic_fetch_sub(&goMemoryBytes, size, __ATOMIC_RELAXED);
+		return NULL;
+	}
+
+	char * memory = malloc(GO_MEMORY_HEADER + size);
+	if (memory == NULL) {
+		__atomic_fetch_sub(&goMemoryBytes, size, __ATOMIC_RELAXED);
+		return NULL;
+	}
+
+	*(size_t *)memory = size;
+	__atomic_fetch_add(&goMemoryAllocations, 1, __ATOMIC_RELAXED);
+	return memory + GO_MEMORY_HEADER;
+}
+
+static void goMemoryFree(void * memory) {
+	if (memory == NULL) {
+		return;
+	}
+
+	char * header = (char *)memory - GO_MEMORY_HEADER;
+	__atomic_fetch_sub(&goMemoryBytes, *(size_t *)header, __ATOMIC_RELAXED);
+	__atomic_fetch_sub(&goMemoryAllocations, 1, __ATOMIC_RELAXED);
+	free(header);
+}
+
+static void goMemorySetLimit(size_t limit) {
+	__atomic_store_n(&goMemoryLimit, limit, __ATOMIC_RELAXED);
+}
+
+static void goMemoryUsage(size_t * bytes, size_t * allocations) {
+	*bytes = __atomic_load_n(&goMemoryBytes, __ATOMIC_RELAXED);
+	*allocations = __atomic_load_n(&goMemoryAllocations, __ATOMIC_RELAXED);
+}
+
+static int goInitializeWithCallbacks(void) {
+	ENetCallbacks callbacks = { goMemoryMalloc, goMemoryFree, goNoMemory };
+	return enet_initialize_with_callbacks(ENET_VERSION, &callbacks);
+}
+*/
+import "C"
+import (
+	"sync/atomic"
+)
+
+// InitializeOptions configures how enet allocates memory
+type InitializeOptions struct {
+	// MemoryLimit is the maximum number of bytes enet may have allocated at once. Any
+	// allocation past this limit fails. A value of 0 means no limit.
+	MemoryLimit uint64
+
+	// OnNoMemory is called when enet fails to allocate memory, including allocations that
+	// fail because of the MemoryLimit. Enet aborts the process when it runs out of memory
+	// by default, but with InitializeWithOptions the failing operation returns an error
+	// instead, after calling OnNoMemory if it is set.
+	OnNoMemory func()
+}
+
+// MemoryUsage is a snapshot of the memory enet has allocated
+type MemoryUsage struct {
+	// Bytes is the number of bytes currently allocated
+	Bytes uint64
+
+	// Allocations is the number of allocations that have not been freed yet
+	Allocations uint64
+}
+
+// noMemoryHandler is the OnNoMemory function passed to InitializeWithOptions.
+var noMemoryHandler atomic.Pointer[func()]
+
+// InitializeWithOptions initializes enet like Initialize, but lets enet allocate memory
+// through hooks that keep track of its usage, enforce a limit, and report failures
+// instead of aborting the process. Since memory allocated before can't be freed through
+// the hooks, this must be called before any hosts or packets are created. Returns
+// ErrAllocatedWithoutHooks if enet has been initialized without the hooks before, or a
+// packet has been created before.
+//
+// Enet keeps allocating through the hooks for the rest of the process, since memory
+// allocated through them may outlive Deinitialize. Once enet is deinitialized, the
+// options are reset to no limit and no OnNoMemory function, and a later Initialize keeps
+// using the hooks with those defaults.
+//
+// Like Initialize, every call must be matched by a call to Deinitialize. Returns
+// ErrAlreadyInitialized if enet has already been initialized.
+func InitializeWithOptions(options InitializeOptions) error {
+	library.Lock()
+	defer library.Unlock()
+
+	if library.initialized {
+		return ErrAlreadyInitialized
+	}
+	if library.unhooked.Load() {
+		return ErrAllocatedWithoutHooks
+	}
+
+	if options.OnNoMemory != nil {
+		noMemoryHandler.Store(&options.OnNoMemory)
+	} else {
+		noMemoryHandler.Store(nil)
+	}
+	C.goMemorySetLimit((C.size_t)(options.MemoryLimit))
+
+	if C.goInitializeWithCallbacks() < 0 {
+		resetMemoryOptions()
+		return ErrInitializeFailed
+	}
+
+	library.hooked.Store(true)
+	library.initialized = true
+	library.references++
+	return nil
+}
+
+// noteAllocation must be called before enet may allocate memory, so InitializeWithOptions
+// can refuse to install its hooks once memory may have been allocated without them.
+func noteAllocation() {
+	if !library.hooked.Load() {
+		library.unhooked.Store(true)
+	}
+}
+
+// resetMemoryOptions resets the options of InitializeWithOptions to their defaults.
+func resetMemoryOptions() {
+	noMemoryHandler.Store(nil)
+	C.goMemorySetLimit(0)
+}
+
+// GetMemoryUsage returns the memory currently allocated by enet. This is only tracked
+// when enet was initialized with InitializeWithOptions.
+func GetMemoryUsage() MemoryUsage {
+	var bytes, allocations C.size_t
+	C.goMemoryUsage(&bytes, &allocations)
+	return MemoryUsage{
+		Bytes:       uint64(bytes),
+		Allocations: uint64(allocations),
+	}
+}
@@ -0,0 +1,12 @@
+package enet
+
+import "C"
+
+// goNoMemory is called by enet through the callbacks set up in InitializeWithOptions.
+//
+//export goNoMemory
+func goNoMemory() {
+	if handler := noMemoryHandler.Load(); handler != nil {
+		(*handler)()
+	}
+}
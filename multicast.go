@@ -0,0 +1,117 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+
+func (host *enetHost) Multicast(packet Packet, channel uint8, peers []Peer) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+
+	p, err := packetFor(packet)
+	if err != nil {
+		return err
+	}
+
+	// Each peer only gets the packet once, even if it is listed more than once.
+	seen := make(map[*enetPeer]bool, len(peers))
+	var result multicastResult
+	for _, peer := range peers {
+		if peer == nil || seen[peer.(*enetPeer)] {
+			continue
+		}
+		seen[peer.(*enetPeer)] = true
+		result.add(host.multicastTo(p, channel, peer.(*enetPeer)))
+	}
+
+	return host.finishMulticast(p, result)
+}
+
+func (host *enetHost) BroadcastExcept(packet Packet, channel uint8, exclude ...Peer) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+
+	p, err := packetFor(packet)
+	if err != nil {
+		return err
+	}
+
+	excluded := make(map[*enetPeer]bool, len(exclude))
+	for _, peer := range exclude {
+		if peer != nil {
+			excluded[peer.(*enetPeer)] = true
+		}
+	}
+
+	var result multicastResult
+	for _, peer := range host.Peers() {
+		if !excluded[peer.(*enetPeer)] {
+			result.add(host.multicastTo(p, channel, peer.(*enetPeer)))
+		}
+	}
+
+	return host.finishMulticast(p, result)
+}
+
+// multicastResult counts the peers a packet has been queued on, and remembers why
+// connected peers didn't take it.
+type multicastResult struct {
+	sent int
+	err  error
+}
+
+// add records the result of queueing the packet on a single peer.
+func (result *multicastResult) add(err error) {
+	if err == nil {
+		result.sent++
+	} else if err != ErrPeerNotConnected {
+		result.err = err
+	}
+}
+
+// multicastTo queues the packet on the peer like sendTo, skipping peers of other hosts.
+func (host *enetHost) multicastTo(p *enetPacket, channel uint8, peer *enetPeer) error {
+	if peer.released() || peer.host != host {
+		return ErrPeerNotConnected
+	}
+
+	return host.sendTo(p, channel, peer.cPeer)
+}
+
+// sendTo queues the packet on an enet peer of this host. Every peer that takes the
+// packet adds a reference to it. Returns the same errors as Peer.SendPacket if the peer
+// can't take the packet.
+func (host *enetHost) sendTo(p *enetPacket, channel uint8, cPeer *C.ENetPeer) error {
+	if cPeer.state != C.ENET_PEER_STATE_CONNECTED {
+		return ErrPeerNotConnected
+	}
+	if (C.size_t)(channel) >= cPeer.channelCount {
+		return ErrInvalidChannel
+	}
+	if p.cPacket.dataLength > host.cHost.maximumPacketSize {
+		return ErrPacketTooLarge
+	}
+
+	if C.enet_peer_send(cPeer, (C.enet_uint8)(channel), p.cPacket) < 0 {
+		return ErrSendFailed
+	}
+	return nil
+}
+
+// finishMulticast takes ownership of the packet after it has been queued on peers. Like
+// enet_host_broadcast, it destroys the packet if nothing references it. If no peer took
+// the packet, it returns why a connected peer rejected it, or ErrNoPeersConnected if
+// there were no connected peers to send to.
+func (host *enetHost) finishMulticast(p *enetPacket, result multicastResult) error {
+	if result.sent == 0 {
+		p.destroyIfUnreferenced()
+		if result.err != nil {
+			return result.err
+		}
+		return ErrNoPeersConnected
+	}
+
+	p.markSent()
+	return nil
+}
@@ -4,6 +4,7 @@ package enet
 import "C"
 import (
 	"errors"
+	"fmt"
 	"unsafe"
 )
 
@@ -19,7 +20,8 @@ const (
 	// for reliable packets
 	PacketFlagUnsequenced PacketFlags = C.ENET_PACKET_FLAG_UNSEQUENCED
 
-	// PacketFlagNoAllocate packets will not allocate data, and user must supply it instead
+	// PacketFlagNoAllocate packets will not allocate data, and user must supply it instead.
+	// Use BufferPool.NewPacket to create these packets.
 	PacketFlagNoAllocate PacketFlags = C.ENET_PACKET_FLAG_NO_ALLOCATE
 
 	// PacketFlagUnreliableFragment packets will be fragmented using unreliable (instead of
@@ -31,47 +33,199 @@ const (
 	PacketFlagSent PacketFlags = C.ENET_PACKET_FLAG_SENT
 )
 
-// Packet may be sent to or received from a peer
+// Packet may be sent to or received from a peer. Once a packet has been sent, enet owns
+// it and destroys it once it has been sent to all peers. Until then, it may be sent to
+// more peers, but can't be used otherwise.
 type Packet interface {
-	Destroy()
+	// Destroy destroys the packet. Returns ErrPacketDestroyed if the packet has already
+	// been destroyed, or ErrPacketSent if it has been sent, in which case nothing happens.
+	Destroy() error
+
+	// GetData returns a copy of the data in the packet, or nil if the packet has been
+	// destroyed or sent.
 	GetData() []byte
+
+	// Bytes returns the data of the packet without copying it, or nil if the packet has
+	// been destroyed or sent. The returned slice points into C memory, and may be written
+	// to. It is only valid until the packet is destroyed, resized or sent. Using it after
+	// that is undefined behavior.
+	Bytes() []byte
+
+	// Resize changes the length of the packet data, keeping its contents up to the new
+	// length. Slices previously returned by Bytes are invalid after this.
+	Resize(length int) error
+
+	// SetFreeCallback sets a function that is called when the packet is freed, either by
+	// Destroy or by enet once it is done sending the packet to all peers. The callback is
+	// called on the goroutine that frees the packet: the one calling Destroy, or the one
+	// servicing the host. It must be set before the packet is sent.
+	SetFreeCallback(callback func()) error
+
+	// GetFlags returns the flags of the packet, or 0 if the packet has been destroyed or
+	// sent.
 	GetFlags() PacketFlags
 }
 
 type enetPacket struct {
+	// cPacket is nil once the packet has been destroyed.
 	cPacket *C.struct__ENetPacket
+
+	// sent is true once the packet has been queued for sending, so enet owns it. Enet
+	// clears cPacket through the free callback when it destroys the packet.
+	sent bool
+
+	// capacity is the size of the buffer backing a packet created by a BufferPool, which
+	// it can't be resized beyond.
+	capacity int
 }
 
-func (packet enetPacket) Destroy() {
+// packetFor returns the enet packet of a packet to send, or ErrPacketDestroyed if it
+// has been destroyed. A packet that has been sent may be sent to more peers until enet
+// destroys it, like enet's reference counting allows.
+func packetFor(packet Packet) (*enetPacket, error) {
+	ret := packet.(*enetPacket)
+	if ret.destroyed() {
+		return nil, ErrPacketDestroyed
+	}
+	return ret, nil
+}
+
+// destroyed returns true if the packet has been destroyed.
+func (packet *enetPacket) destroyed() bool {
+	return packet.cPacket == nil
+}
+
+// usable returns ErrPacketDestroyed or ErrPacketSent if the packet can't be used anymore.
+func (packet *enetPacket) usable() error {
+	if packet.destroyed() {
+		return ErrPacketDestroyed
+	}
+	if packet.sent {
+		return ErrPacketSent
+	}
+	return nil
+}
+
+func (packet *enetPacket) Destroy() error {
+	if err := packet.usable(); err != nil {
+		return err
+	}
+
+	debugUntrackPacket(packet.cPacket)
 	C.enet_packet_destroy(packet.cPacket)
+	packet.cPacket = nil
+	return nil
+}
+
+// markSent records that enet has taken ownership of the packet after queueing it for at
+// least one peer. A received packet is no longer tracked as a possible leak from then on.
+func (packet *enetPacket) markSent() {
+	if !packet.sent {
+		packet.freeState().packet = packet
+	}
+	packet.sent = true
+	debugUntrackPacket(packet.cPacket)
 }
 
-func (packet enetPacket) GetData() []byte {
+// isReferenced returns true if enet has taken ownership of the packet by queueing it
+// for at least one peer. Enet destroys referenced packets once they have been sent.
+func (packet *enetPacket) isReferenced() bool {
+	return packet.cPacket.referenceCount > 0
+}
+
+// destroyIfUnreferenced destroys the packet if enet has not taken ownership of it, for
+// example because sending it failed.
+func (packet *enetPacket) destroyIfUnreferenced() {
+	if !packet.isReferenced() {
+		packet.Destroy()
+	}
+}
+
+func (packet *enetPacket) GetData() []byte {
+	if packet.usable() != nil {
+		return nil
+	}
+
 	return C.GoBytes(
 		unsafe.Pointer(packet.cPacket.data),
 		(C.int)(packet.cPacket.dataLength),
 	)
 }
 
-func (packet enetPacket) GetFlags() PacketFlags {
+func (packet *enetPacket) Bytes() []byte {
+	if packet.usable() != nil {
+		return nil
+	}
+
+	return unsafe.Slice((*byte)(packet.cPacket.data), packet.cPacket.dataLength)
+}
+
+func (packet *enetPacket) Resize(length int) error {
+	if err := packet.usable(); err != nil {
+		return err
+	}
+
+	if length < 0 {
+		return errors.New("packet length must not be negative")
+	}
+
+	// Enet doesn't know how large the buffer of a packet without allocated data is.
+	if packet.GetFlags()&PacketFlagNoAllocate != 0 && length > packet.capacity {
+		return fmt.Errorf("packet can't be resized beyond its buffer of %d bytes", packet.capacity)
+	}
+
+	if C.enet_packet_resize(packet.cPacket, (C.size_t)(length)) < 0 {
+		return errors.New("unable to resize packet")
+	}
+
+	return nil
+}
+
+func (packet *enetPacket) GetFlags() PacketFlags {
+	if packet.usable() != nil {
+		return 0
+	}
+
 	return (PacketFlags)(packet.cPacket.flags)
 }
 
 // NewPacket creates a new packet to send to peers
 func NewPacket(data []byte, flags PacketFlags) (Packet, error) {
-	buffer := C.CBytes(data)
+	packet, err := NewPacketSize(len(data), flags)
+	if err != nil {
+		return nil, err
+	}
+
+	copy(packet.Bytes(), data)
+	return packet, nil
+}
+
+// NewPacketSize creates a new packet with the given length of uninitialized data, which
+// can be filled in place through Packet.Bytes before sending it. This avoids copying the
+// data into the packet.
+func NewPacketSize(length int, flags PacketFlags) (Packet, error) {
+	if length < 0 {
+		return nil, errors.New("packet length must not be negative")
+	}
+
+	if flags&PacketFlagNoAllocate != 0 {
+		return nil, errors.New("packets created with PacketFlagNoAllocate need user supplied data")
+	}
+
+	noteAllocation()
 	packet := C.enet_packet_create(
-		buffer,
-		(C.size_t)(len(data)),
+		nil,
+		(C.size_t)(length),
 		(C.enet_uint32)(flags),
 	)
-	C.free(buffer)
 
 	if packet == nil {
 		return nil, errors.New("unable to create packet")
 	}
 
-	return enetPacket{
+	ret := &enetPacket{
 		cPacket: packet,
-	}, nil
+	}
+	debugTrackNewPacket(ret)
+	return ret, nil
 }
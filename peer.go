@@ -4,32 +4,132 @@ package enet
 import "C"
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
+	"sync"
+	"time"
 	"unsafe"
 )
 
-// Peer is a peer which data packets may be sent or received from
+const (
+	// PeerTimeoutLimit is the default timeout limit of a peer
+	PeerTimeoutLimit = C.ENET_PEER_TIMEOUT_LIMIT
+
+	// PeerTimeoutMinimum is the default minimum timeout of a peer
+	PeerTimeoutMinimum = C.ENET_PEER_TIMEOUT_MINIMUM * time.Millisecond
+
+	// PeerTimeoutMaximum is the default maximum timeout of a peer
+	PeerTimeoutMaximum = C.ENET_PEER_TIMEOUT_MAXIMUM * time.Millisecond
+
+	// PeerPingInterval is the default interval at which reliable pings are sent to a peer
+	PeerPingInterval = C.ENET_PEER_PING_INTERVAL * time.Millisecond
+
+	// PacketThrottleScale is the value of a packet throttle that lets all unreliable
+	// packets through. Throttle acceleration and deceleration are relative to this scale.
+	PacketThrottleScale = C.ENET_PEER_PACKET_THROTTLE_SCALE
+)
+
+// ThrottleConfig configures the unreliable packet throttle of a peer
+type ThrottleConfig struct {
+	// Interval over which to measure the lowest mean round trip time used by the throttle
+	Interval time.Duration
+
+	// Acceleration is the rate at which to increase the throttle probability as mean
+	// round trip time declines, between 1 and PacketThrottleScale
+	Acceleration uint32
+
+	// Deceleration is the rate at which to decrease the throttle probability as mean
+	// round trip time increases, between 1 and PacketThrottleScale
+	Deceleration uint32
+}
+
+// Peer is a peer which data packets may be sent or received from.
+//
+// The same Peer is returned by every event of a connection, so it can be compared and
+// used as a map key. Once the connection ends, the peer is released: after the disconnect
+// event has been handled, or immediately after DisconnectNow or Reset. A released peer
+// can no longer send, GetAddress and GetConnectId return their last known values, and
+// any value or data set against it is cleared. Connecting from a disconnect handler may
+// reuse the enet peer of the event, in which case the disconnected peer already loses
+// its data, but keeps its value until it is released.
 type Peer interface {
 	GetAddress() Address
 	GetConnectId() uint
 
+	// State returns the state of the connection with this peer. Released peers are
+	// always disconnected.
+	State() PeerState
+
+	// Stats returns a snapshot of the round trip time, packet loss, throttle and
+	// bandwidth counters of this peer.
+	Stats() PeerStats
+
+	// SetTimeout sets the timeout parameters of this peer. The limit is a multiplier of the
+	// round trip time after which an unacknowledged reliable packet times out. If it does
+	// and the peer has not responded for at least minimum, or if the peer has not responded
+	// for maximum at all, the peer is disconnected. A zero value uses the default.
+	SetTimeout(limit uint32, minimum, maximum time.Duration) error
+
+	// SetPingInterval sets the interval at which pings are sent to this peer while there
+	// is no other traffic. A zero value uses the default.
+	SetPingInterval(interval time.Duration) error
+
+	// Ping sends a ping request to this peer, which is used to update its round trip time.
+	Ping()
+
+	// ConfigureThrottle configures the unreliable packet throttle of this peer. The
+	// configuration is also sent to the peer at the other end of the connection.
+	ConfigureThrottle(config ThrottleConfig) error
+
 	Disconnect(data uint32)
-	DisconnectNow(data uint32)
 	DisconnectLater(data uint32)
 
+	// DisconnectNow forcefully disconnects this peer. The peer at the other end is not
+	// guaranteed to be notified, and no disconnect event is generated. The peer is released
+	// immediately.
+	DisconnectNow(data uint32)
+
+	// Reset forcefully disconnects this peer without notifying the peer at the other end,
+	// and without generating a disconnect event. The peer is released immediately.
+	Reset()
+
 	SendBytes(data []byte, channel uint8, flags PacketFlags) error
 	SendString(str string, channel uint8, flags PacketFlags) error
+
+	// SendPacket queues a packet to be sent to this peer. On success, enet takes ownership
+	// of the packet and destroys it once it has been sent. On failure, the caller keeps
+	// ownership and is responsible for destroying the packet, unless it had been sent
+	// before. The same packet may be sent to several peers until enet destroys it, after
+	// which ErrPacketDestroyed is returned.
 	SendPacket(packet Packet, channel uint8) error
 
+	// Receive returns the next packet received from this peer and the channel it was
+	// received on, or false if there is none. The packet must be destroyed with
+	// Packet.Destroy after use.
+	//
+	// If the host queues receives per peer, this returns the queued packets, and may be
+	// called from any goroutine. Otherwise, this takes packets from enet directly that have
+	// not been returned in a receive event yet, and must be called from the goroutine
+	// servicing the host. Queued packets that have not been received when the peer is
+	// released are destroyed.
+	Receive() (packet Packet, channel uint8, ok bool)
+
+	// SetValue sets an arbitrary Go value against a peer, such as a player session. The
+	// value is kept until the peer is released.
+	SetValue(value any)
+
+	// Value returns the value that has been set with SetValue, or nil if no value has
+	// been set or the peer has been released.
+	Value() any
+
 	// SetData sets an arbitrary value against a peer. This is useful to attach some
 	// application-specific data for future use, such as an identifier.
 	//
 	// http://enet.bespin.org/structENetPeer.html#a1873959810db7ac7a02da90469ee384e
 	//
-	// Note that due to the way the enet library works, if using this you are
-	// responsible for clearing this data when the peer is finished with.
-	// SetData(nil) will free underlying memory and avoid any leaks.
+	// The data is copied into C memory, which is freed when the peer is released.
+	// SetData(nil) frees it immediately.
 	//
 	// See http://enet.bespin.org/Tutorial.html#ManageHost for an example of this
 	// in the underlying library.
@@ -43,70 +143,304 @@ type Peer interface {
 }
 
 type enetPeer struct {
+	// cPeer is nil once the peer has been released.
 	cPeer *C.struct__ENetPeer
+	host  *enetHost
+	value any
+
+	// queue holds the packets received for this peer while its host queues receives per
+	// peer. It may be accessed from any goroutine while holding queueMutex.
+	queueMutex sync.Mutex
+	queue      []queuedPacket
+
+	// connectID identifies the connection this handle belongs to, since enet clears it
+	// before returning the disconnect event. The address is only set once the peer is
+	// detached, to keep the last known address around.
+	connectID C.enet_uint32
+	address   C.struct__ENetAddress
+}
+
+// released returns true if the connection of this peer has ended.
+func (peer *enetPeer) released() bool {
+	return peer.cPeer == nil
+}
+
+// release clears everything attached to the peer and detaches it from the underlying
+// enet peer, which may be reused for a new connection after this.
+func (peer *enetPeer) release() {
+	peer.detach()
+	peer.value = nil
+}
+
+// detach frees the C data and queued packets of the peer and detaches it from the
+// underlying enet peer, but keeps its value until it is released.
+func (peer *enetPeer) detach() {
+	if peer.released() {
+		return
+	}
+
+	peer.SetData(nil)
+	peer.destroyQueue()
+	peer.address = peer.cPeer.address
+	peer.cPeer = nil
+}
+
+// releaseIfReset releases the peer if enet reset it without generating a disconnect
+// event, which happens when disconnecting a peer that isn't fully connected yet.
+func (peer *enetPeer) releaseIfReset() {
+	if peer.cPeer.state == C.ENET_PEER_STATE_DISCONNECTED {
+		peer.host.releasePeer(peer)
+	}
 }
 
-func (peer enetPeer) GetAddress() Address {
+func (peer *enetPeer) GetAddress() Address {
+	if peer.released() {
+		return &enetAddress{
+			cAddr: peer.address,
+		}
+	}
+
 	return &enetAddress{
 		cAddr: peer.cPeer.address,
 	}
 }
 
-func (peer enetPeer) GetConnectId() uint {
-	return uint(peer.cPeer.connectID)
+func (peer *enetPeer) GetConnectId() uint {
+	return uint(peer.connectID)
 }
 
-func (peer enetPeer) Disconnect(data uint32) {
-	C.enet_peer_disconnect(
-		peer.cPeer,
-		(C.enet_uint32)(data),
-	)
+// durationToMilliseconds converts a duration to the milliseconds enet expects, making
+// sure it fits and isn't silently rounded down to 0.
+func durationToMilliseconds(d time.Duration, name string) (C.enet_uint32, error) {
+	if d < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+	if d > 0 && d < time.Millisecond {
+		return 0, fmt.Errorf("%s must be at least 1 millisecond", name)
+	}
+	ms := d.Milliseconds()
+	if ms > math.MaxUint32 {
+		return 0, fmt.Errorf("%s must be at most %d milliseconds", name, uint32(math.MaxUint32))
+	}
+	return (C.enet_uint32)(ms), nil
 }
 
-func (peer enetPeer) DisconnectNow(data uint32) {
-	C.enet_peer_disconnect_now(
+func (peer *enetPeer) SetTimeout(limit uint32, minimum, maximum time.Duration) error {
+	if peer.released() {
+		return ErrPeerNotConnected
+	}
+
+	cMinimum, err := durationToMilliseconds(minimum, "timeout minimum")
+	if err != nil {
+		return err
+	}
+
+	cMaximum, err := durationToMilliseconds(maximum, "timeout maximum")
+	if err != nil {
+		return err
+	}
+
+	// Enet uses the defaults for zero values, which must be compared as well.
+	if minimum == 0 {
+		minimum = PeerTimeoutMinimum
+	}
+	if maximum == 0 {
+		maximum = PeerTimeoutMaximum
+	}
+	if minimum > maximum {
+		return errors.New("timeout minimum must not be greater than timeout maximum")
+	}
+
+	C.enet_peer_timeout(
 		peer.cPeer,
-		(C.enet_uint32)(data),
+		(C.enet_uint32)(limit),
+		cMinimum,
+		cMaximum,
 	)
+	return nil
+}
+
+func (peer *enetPeer) SetPingInterval(interval time.Duration) error {
+	if peer.released() {
+		return ErrPeerNotConnected
+	}
+
+	cInterval, err := durationToMilliseconds(interval, "ping interval")
+	if err != nil {
+		return err
+	}
+
+	C.enet_peer_ping_interval(peer.cPeer, cInterval)
+	return nil
 }
 
-func (peer enetPeer) DisconnectLater(data uint32) {
-	C.enet_peer_disconnect_later(
+func (peer *enetPeer) Ping() {
+	if peer.released() {
+		return
+	}
+
+	C.enet_peer_ping(peer.cPeer)
+}
+
+func (peer *enetPeer) ConfigureThrottle(config ThrottleConfig) error {
+	if peer.released() {
+		return ErrPeerNotConnected
+	}
+
+	if config.Interval <= 0 {
+		return errors.New("throttle interval must be positive")
+	}
+
+	cInterval, err := durationToMilliseconds(config.Interval, "throttle interval")
+	if err != nil {
+		return err
+	}
+
+	if config.Acceleration < 1 || config.Acceleration > PacketThrottleScale {
+		return fmt.Errorf("throttle acceleration must be between 1 and %d", PacketThrottleScale)
+	}
+
+	if config.Deceleration < 1 || config.Deceleration > PacketThrottleScale {
+		return fmt.Errorf("throttle deceleration must be between 1 and %d", PacketThrottleScale)
+	}
+
+	C.enet_peer_throttle_configure(
 		peer.cPeer,
-		(C.enet_uint32)(data),
+		cInterval,
+		(C.enet_uint32)(config.Acceleration),
+		(C.enet_uint32)(config.Deceleration),
 	)
+	return nil
+}
+
+func (peer *enetPeer) Disconnect(data uint32) {
+	if peer.released() {
+		return
+	}
+
+	// Disconnecting may flush the host.
+	peer.host.withChecksum(func() {
+		C.enet_peer_disconnect(
+			peer.cPeer,
+			(C.enet_uint32)(data),
+		)
+	})
+	peer.releaseIfReset()
+}
+
+func (peer *enetPeer) DisconnectNow(data uint32) {
+	if peer.released() {
+		return
+	}
+
+	peer.host.withChecksum(func() {
+		C.enet_peer_disconnect_now(
+			peer.cPeer,
+			(C.enet_uint32)(data),
+		)
+	})
+	peer.host.releasePeer(peer)
+}
+
+func (peer *enetPeer) DisconnectLater(data uint32) {
+	if peer.released() {
+		return
+	}
+
+	peer.host.withChecksum(func() {
+		C.enet_peer_disconnect_later(
+			peer.cPeer,
+			(C.enet_uint32)(data),
+		)
+	})
+	peer.releaseIfReset()
 }
 
-func (peer enetPeer) SendBytes(data []byte, channel uint8, flags PacketFlags) error {
+func (peer *enetPeer) Reset() {
+	if peer.released() {
+		return
+	}
+
+	C.enet_peer_reset(peer.cPeer)
+	peer.host.releasePeer(peer)
+}
+
+func (peer *enetPeer) SendBytes(data []byte, channel uint8, flags PacketFlags) error {
 	packet, err := NewPacket(data, flags)
 	if err != nil {
 		return err
 	}
-	return peer.SendPacket(packet, channel)
+
+	err = peer.SendPacket(packet, channel)
+	if err != nil {
+		// The packet was created here, so nobody else can destroy it.
+		packet.(*enetPacket).destroyIfUnreferenced()
+	}
+	return err
 }
 
-func (peer enetPeer) SendString(str string, channel uint8, flags PacketFlags) error {
-	packet, err := NewPacket([]byte(str), flags)
+func (peer *enetPeer) SendString(str string, channel uint8, flags PacketFlags) error {
+	return peer.SendBytes([]byte(str), channel, flags)
+}
+
+func (peer *enetPeer) SendPacket(packet Packet, channel uint8) error {
+	if peer.released() {
+		return ErrPeerNotConnected
+	}
+
+	p, err := packetFor(packet)
 	if err != nil {
 		return err
 	}
-	return peer.SendPacket(packet, channel)
-}
+	cPacket := p.cPacket
+
+	// Check the most common reasons for enet_peer_send to fail up front, so we can
+	// return a more specific error.
+	if peer.cPeer.state != C.ENET_PEER_STATE_CONNECTED {
+		return ErrPeerNotConnected
+	}
+	if (C.size_t)(channel) >= peer.cPeer.channelCount {
+		return ErrInvalidChannel
+	}
+	if cPacket.dataLength > peer.cPeer.host.maximumPacketSize {
+		return ErrPacketTooLarge
+	}
 
-func (peer enetPeer) SendPacket(packet Packet, channel uint8) error {
-	C.enet_peer_send(
+	status := C.enet_peer_send(
 		peer.cPeer,
 		(C.enet_uint8)(channel),
-		packet.(enetPacket).cPacket,
+		cPacket,
 	)
+
+	if status < 0 {
+		return ErrSendFailed
+	}
+
+	p.markSent()
 	return nil
 }
 
-func (peer enetPeer) SetData(data []byte) {
+func (peer *enetPeer) SetValue(value any) {
+	if peer.released() {
+		return
+	}
+
+	peer.value = value
+}
+
+func (peer *enetPeer) Value() any {
+	return peer.value
+}
+
+func (peer *enetPeer) SetData(data []byte) {
 	if len(data) > math.MaxUint32 {
 		panic(fmt.Sprintf("maximum peer data length is uint32 (%d)", math.MaxUint32))
 	}
 
+	if peer.released() {
+		return
+	}
+
 	// Free any data that was previously stored against this peer.
 	existing := unsafe.Pointer(peer.cPeer.data)
 	if existing != nil {
@@ -129,7 +463,11 @@ func (peer enetPeer) SetData(data []byte) {
 	peer.cPeer.data = unsafe.Pointer(C.CBytes(b))
 }
 
-func (peer enetPeer) GetData() []byte {
+func (peer *enetPeer) GetData() []byte {
+	if peer.released() {
+		return nil
+	}
+
 	ptr := unsafe.Pointer(peer.cPeer.data)
 
 	if ptr == nil {
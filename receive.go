@@ -0,0 +1,70 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+
+// queuedPacket is a packet received while the host queues receives per peer.
+type queuedPacket struct {
+	packet  *enetPacket
+	channel uint8
+}
+
+func (host *enetHost) SetReceiveQueueing(enabled bool) {
+	host.queueReceives.Store(enabled)
+}
+
+// queueReceive queues the packet of a receive event on its peer if the host queues
+// receives per peer. Returns true if the event has been consumed.
+func (host *enetHost) queueReceive(ev *enetEvent) bool {
+	if !host.queueReceives.Load() || ev.GetType() != EventReceive {
+		return false
+	}
+
+	peer := ev.peer
+	peer.queueMutex.Lock()
+	peer.queue = append(peer.queue, queuedPacket{
+		packet:  ev.packet,
+		channel: ev.GetChannelID(),
+	})
+	peer.queueMutex.Unlock()
+	return true
+}
+
+func (peer *enetPeer) Receive() (Packet, uint8, bool) {
+	peer.queueMutex.Lock()
+	if len(peer.queue) > 0 {
+		queued := peer.queue[0]
+		peer.queue[0] = queuedPacket{}
+		peer.queue = peer.queue[1:]
+		peer.queueMutex.Unlock()
+		return queued.packet, queued.channel, true
+	}
+	peer.queueMutex.Unlock()
+
+	if peer.host.queueReceives.Load() || peer.released() {
+		return nil, 0, false
+	}
+
+	var channel C.enet_uint8
+	cPacket := C.enet_peer_receive(peer.cPeer, &channel)
+	if cPacket == nil {
+		return nil, 0, false
+	}
+
+	debugTrackPacket(peer.host.cHost, cPacket)
+	return &enetPacket{
+		cPacket: cPacket,
+	}, uint8(channel), true
+}
+
+// destroyQueue destroys any packets that have been queued for the peer but not received.
+func (peer *enetPeer) destroyQueue() {
+	peer.queueMutex.Lock()
+	queue := peer.queue
+	peer.queue = nil
+	peer.queueMutex.Unlock()
+
+	for _, queued := range queue {
+		queued.packet.Destroy()
+	}
+}
@@ -0,0 +1,114 @@
+package enet
+
+import (
+	"context"
+)
+
+// runServiceTimeout is the number of milliseconds Host.Run waits for events before
+// checking whether its context has been cancelled.
+const runServiceTimeout = 10
+
+// EventHandler is called by Host.Run for every event that occurs on the host
+type EventHandler func(ev Event)
+
+func (host *enetHost) Run(ctx context.Context, handler EventHandler) error {
+	return host.run(ctx, handler, nil)
+}
+
+// run services the host until the context is cancelled. If tick is not nil, it is called
+// on every iteration of the loop before servicing the host, and once more before the
+// host is flushed and destroyed.
+func (host *enetHost) run(ctx context.Context, handler EventHandler, tick func()) error {
+	if host.destroyed() {
+		return ErrHostDestroyed
+	}
+
+	defer host.Destroy()
+
+	for {
+		if tick != nil {
+			tick()
+		}
+
+		select {
+		case <-ctx.Done():
+			host.Flush()
+			return nil
+		default:
+		}
+
+		events, err := host.ServiceAll(runServiceTimeout)
+		if err != nil {
+			return err
+		}
+
+		for _, ev := range events {
+			handler(ev)
+		}
+	}
+}
+
+// EventStream delivers the events of a host that is serviced on another goroutine, as
+// returned by Host.Events
+type EventStream struct {
+	events chan Event
+
+	// done receives a value once the event last returned by Next has been handled.
+	done     chan struct{}
+	handling bool
+
+	// err is the error returned by Host.Run, set before events is closed.
+	err error
+}
+
+// Next waits for the next event and returns it. The previous event returned by Next must
+// not be used after this. Returns false once the host has stopped running, after which
+// Err returns why.
+func (stream *EventStream) Next() (Event, bool) {
+	if stream.handling {
+		stream.done <- struct{}{}
+		stream.handling = false
+	}
+
+	ev, ok := <-stream.events
+	stream.handling = ok
+	return ev, ok
+}
+
+// Err returns the error that stopped the host, or nil if it was stopped by cancelling its
+// context. This is only valid once Next has returned false.
+func (stream *EventStream) Err() error {
+	return stream.err
+}
+
+func (host *enetHost) Events(ctx context.Context) *EventStream {
+	stream := &EventStream{
+		events: make(chan Event),
+		done:   make(chan struct{}, 1),
+	}
+
+	go func() {
+		defer close(stream.events)
+
+		stream.err = host.Run(ctx, func(ev Event) {
+			select {
+			case stream.events <- ev:
+			case <-ctx.Done():
+				// Nobody is going to receive this event anymore, so make sure its
+				// packet doesn't leak.
+				if ev.GetType() == EventReceive {
+					ev.GetPacket().Destroy()
+				}
+				return
+			}
+
+			// Don't service the host again until the receiver is done with the event.
+			select {
+			case <-stream.done:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	return stream
+}
@@ -0,0 +1,195 @@
+package enet
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// Future is the result of a command queued on a SafeHost, which becomes available once
+// the command has been executed on the service goroutine
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{
+		done: make(chan struct{}),
+	}
+}
+
+func (future *Future[T]) resolve(value T, err error) {
+	future.value = value
+	future.err = err
+	close(future.done)
+}
+
+// Done returns a channel that is closed once the result is available
+func (future *Future[T]) Done() <-chan struct{} {
+	return future.done
+}
+
+// Wait blocks until the command has been executed and returns its result
+func (future *Future[T]) Wait() (T, error) {
+	<-future.done
+	return future.value, future.err
+}
+
+// Err blocks until the command has been executed and returns its error
+func (future *Future[T]) Err() error {
+	<-future.done
+	return future.err
+}
+
+// safeCommand is called with the host on the service goroutine, or with nil if the host
+// was destroyed before the command could be executed.
+type safeCommand func(host Host)
+
+// SafeHost wraps a Host so that it can be used from multiple goroutines. Commands are
+// queued and executed on the goroutine calling SafeHost.Run, in between servicing the
+// host, with their results returned as a Future.
+//
+// The event handler passed to Run is called on the service goroutine, so it may use the
+// host and peers in the events directly. It must not wait on a Future, since the command
+// can only be executed after the handler returns.
+type SafeHost struct {
+	host *enetHost
+
+	mutex  sync.Mutex
+	queue  []safeCommand
+	closed bool
+}
+
+// NewSafeHost wraps a host for use from multiple goroutines. After this, the host must
+// only be used through the returned SafeHost.
+func NewSafeHost(host Host) *SafeHost {
+	return &SafeHost{
+		host: host.(*enetHost),
+	}
+}
+
+// Run services the host until the context is cancelled, calling handler for every event
+// and executing queued commands. Commands that are still queued when the host is
+// destroyed fail with ErrHostDestroyed. See Host.Run.
+func (safe *SafeHost) Run(ctx context.Context, handler EventHandler) error {
+	defer safe.close()
+	return safe.host.run(ctx, handler, safe.execute)
+}
+
+// Do queues fn to be called with the host on the service goroutine. Peers obtained from
+// the host may be used within fn.
+func (safe *SafeHost) Do(fn func(host Host) error) *Future[struct{}] {
+	return submit(safe, func(host Host) (struct{}, error) {
+		return struct{}{}, fn(host)
+	})
+}
+
+// Connect queues a connection to a foreign host. See Host.Connect.
+func (safe *SafeHost) Connect(addr net.Addr, channelCount int, data uint32) *Future[Peer] {
+	return submit(safe, func(host Host) (Peer, error) {
+		return host.Connect(addr, channelCount, data)
+	})
+}
+
+// SendBytes queues data to be sent to a peer. See Peer.SendBytes.
+func (safe *SafeHost) SendBytes(peer Peer, data []byte, channel uint8, flags PacketFlags) *Future[struct{}] {
+	// Copy the data, since the caller may reuse it before the command is executed.
+	data = append([]byte(nil), data...)
+	return safe.Do(func(Host) error {
+		return peer.SendBytes(data, channel, flags)
+	})
+}
+
+// BroadcastBytes queues data to be sent to all connected peers. See Host.BroadcastBytes.
+func (safe *SafeHost) BroadcastBytes(data []byte, channel uint8, flags PacketFlags) *Future[struct{}] {
+	data = append([]byte(nil), data...)
+	return safe.Do(func(host Host) error {
+		return host.BroadcastBytes(data, channel, flags)
+	})
+}
+
+// Disconnect queues a disconnection request for a peer. See Peer.Disconnect.
+func (safe *SafeHost) Disconnect(peer Peer, data uint32) *Future[struct{}] {
+	return safe.Do(func(Host) error {
+		peer.Disconnect(data)
+		return nil
+	})
+}
+
+// DisconnectNow queues an immediate disconnection of a peer. See Peer.DisconnectNow.
+func (safe *SafeHost) DisconnectNow(peer Peer, data uint32) *Future[struct{}] {
+	return safe.Do(func(Host) error {
+		peer.DisconnectNow(data)
+		return nil
+	})
+}
+
+// SetTimeout queues new timeout parameters for a peer. See Peer.SetTimeout.
+func (safe *SafeHost) SetTimeout(peer Peer, limit uint32, minimum, maximum time.Duration) *Future[struct{}] {
+	return safe.Do(func(Host) error {
+		return peer.SetTimeout(limit, minimum, maximum)
+	})
+}
+
+// SetBandwidthLimit queues a new bandwidth limit for the host. See Host.SetBandwidthLimit.
+func (safe *SafeHost) SetBandwidthLimit(incomingBandwidth, outgoingBandwidth uint32) *Future[struct{}] {
+	return safe.Do(func(host Host) error {
+		host.SetBandwidthLimit(incomingBandwidth, outgoingBandwidth)
+		return nil
+	})
+}
+
+// submit queues fn on the host, returning a future for its result.
+func submit[T any](safe *SafeHost, fn func(host Host) (T, error)) *Future[T] {
+	ret := newFuture[T]()
+
+	command := func(host Host) {
+		if host == nil {
+			var zero T
+			ret.resolve(zero, ErrHostDestroyed)
+			return
+		}
+		ret.resolve(fn(host))
+	}
+
+	safe.mutex.Lock()
+	closed := safe.closed
+	if !closed {
+		safe.queue = append(safe.queue, command)
+	}
+	safe.mutex.Unlock()
+
+	if closed {
+		command(nil)
+	}
+
+	return ret
+}
+
+// execute runs all queued commands on the service goroutine.
+func (safe *SafeHost) execute() {
+	safe.mutex.Lock()
+	queue := safe.queue
+	safe.queue = nil
+	safe.mutex.Unlock()
+
+	for _, command := range queue {
+		command(safe.host)
+	}
+}
+
+// close fails all queued commands and any commands submitted afterwards.
+func (safe *SafeHost) close() {
+	safe.mutex.Lock()
+	queue := safe.queue
+	safe.queue = nil
+	safe.closed = true
+	safe.mutex.Unlock()
+
+	for _, command := range queue {
+		command(nil)
+	}
+}
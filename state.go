@@ -0,0 +1,206 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// PeerState is the state of the connection with a peer
+type PeerState int
+
+const (
+	// PeerStateDisconnected means the peer is not connected
+	PeerStateDisconnected PeerState = C.ENET_PEER_STATE_DISCONNECTED
+
+	// PeerStateConnecting means a connection request has been sent to the peer
+	PeerStateConnecting PeerState = C.ENET_PEER_STATE_CONNECTING
+
+	// PeerStateAcknowledgingConnect means a connection request has been received from the
+	// peer and is being acknowledged
+	PeerStateAcknowledgingConnect PeerState = C.ENET_PEER_STATE_ACKNOWLEDGING_CONNECT
+
+	// PeerStateConnectionPending means the connection has been acknowledged, but the
+	// connect event has not been returned by Host.Service yet
+	PeerStateConnectionPending PeerState = C.ENET_PEER_STATE_CONNECTION_PENDING
+
+	// PeerStateConnectionSucceeded means the peer has accepted our connection request, but
+	// the connect event has not been returned by Host.Service yet
+	PeerStateConnectionSucceeded PeerState = C.ENET_PEER_STATE_CONNECTION_SUCCEEDED
+
+	// PeerStateConnected means the peer is connected
+	PeerStateConnected PeerState = C.ENET_PEER_STATE_CONNECTED
+
+	// PeerStateDisconnectLater means the peer will be disconnected once all queued
+	// packets have been sent
+	PeerStateDisconnectLater PeerState = C.ENET_PEER_STATE_DISCONNECT_LATER
+
+	// PeerStateDisconnecting means a disconnection request has been sent to the peer
+	PeerStateDisconnecting PeerState = C.ENET_PEER_STATE_DISCONNECTING
+
+	// PeerStateAcknowledgingDisconnect means a disconnection request has been received
+	// from the peer and is being acknowledged
+	PeerStateAcknowledgingDisconnect PeerState = C.ENET_PEER_STATE_ACKNOWLEDGING_DISCONNECT
+
+	// PeerStateZombie means the peer has disconnected, but the disconnect event has not
+	// been returned by Host.Service yet
+	PeerStateZombie PeerState = C.ENET_PEER_STATE_ZOMBIE
+)
+
+func (state PeerState) String() string {
+	switch state {
+	case PeerStateDisconnected:
+		return "disconnected"
+	case PeerStateConnecting:
+		return "connecting"
+	case PeerStateAcknowledgingConnect:
+		return "acknowledging connect"
+	case PeerStateConnectionPending:
+		return "connection pending"
+	case PeerStateConnectionSucceeded:
+		return "connection succeeded"
+	case PeerStateConnected:
+		return "connected"
+	case PeerStateDisconnectLater:
+		return "disconnect later"
+	case PeerStateDisconnecting:
+		return "disconnecting"
+	case PeerStateAcknowledgingDisconnect:
+		return "acknowledging disconnect"
+	case PeerStateZombie:
+		return "zombie"
+	}
+	return fmt.Sprintf("PeerState(%d)", int(state))
+}
+
+// StateChangeFunc is called when the state of a peer changes
+type StateChangeFunc func(peer Peer, from, to PeerState)
+
+// observedPeer is the last state seen of a peer allocated by a host.
+type observedPeer struct {
+	peer  *enetPeer
+	state PeerState
+}
+
+func (peer *enetPeer) State() PeerState {
+	if peer.released() {
+		return PeerStateDisconnected
+	}
+
+	return PeerState(peer.cPeer.state)
+}
+
+func (host *enetHost) SetStateChangeCallback(callback StateChangeFunc) {
+	host.stateChange = callback
+	host.observedPeers = nil
+	if callback == nil || host.destroyed() {
+		return
+	}
+
+	// Start from the current states, so only changes from now on are reported.
+	cPeers := host.cPeers()
+	host.observedPeers = make([]observedPeer, len(cPeers))
+	for i := range cPeers {
+		slot := &host.observedPeers[i]
+		slot.state = PeerState(cPeers[i].state)
+		if slot.state != PeerStateDisconnected {
+			slot.peer = host.peerFor(&cPeers[i])
+		}
+	}
+}
+
+// cPeers returns all peers allocated by the host.
+func (host *enetHost) cPeers() []C.ENetPeer {
+	return unsafe.Slice(host.cHost.peers, host.cHost.peerCount)
+}
+
+// checkStateChanges calls the state change callback for every peer whose state has
+// changed since the last time this was called.
+func (host *enetHost) checkStateChanges() {
+	if host.stateChange == nil {
+		return
+	}
+
+	cPeers := host.cPeers()
+	if host.observedPeers == nil {
+		host.observedPeers = make([]observedPeer, len(cPeers))
+	}
+
+	for i := range cPeers {
+		cPeer := &cPeers[i]
+		slot := &host.observedPeers[i]
+
+		state := PeerState(cPeer.state)
+		if state == slot.state {
+			continue
+		}
+
+		// Report a disconnection with the peer of the connection that ended.
+		peer := slot.peer
+		if state != PeerStateDisconnected {
+			peer = host.peerFor(cPeer)
+		}
+
+		from := slot.state
+		slot.state = state
+		slot.peer = peer
+
+		if peer != nil {
+			host.stateChange(peer, from, state)
+		}
+	}
+}
+
+// PeerSlot is one of the peers allocated by a host
+type PeerSlot struct {
+	// Peer is the peer using the slot, or nil if the slot is disconnected
+	Peer Peer
+
+	// State is the state of the peer using the slot
+	State PeerState
+}
+
+// isConnected returns true if enet counts the peer as connected.
+func isConnected(cPeer *C.ENetPeer) bool {
+	return cPeer.state == C.ENET_PEER_STATE_CONNECTED || cPeer.state == C.ENET_PEER_STATE_DISCONNECT_LATER
+}
+
+func (host *enetHost) Peers() []Peer {
+	if host.destroyed() {
+		return nil
+	}
+
+	var ret []Peer
+	cPeers := host.cPeers()
+	for i := range cPeers {
+		if isConnected(&cPeers[i]) {
+			ret = append(ret, host.peerFor(&cPeers[i]))
+		}
+	}
+	return ret
+}
+
+func (host *enetHost) AllPeers() []PeerSlot {
+	if host.destroyed() {
+		return nil
+	}
+
+	cPeers := host.cPeers()
+	ret := make([]PeerSlot, len(cPeers))
+	for i := range cPeers {
+		ret[i].State = PeerState(cPeers[i].state)
+		if ret[i].State != PeerStateDisconnected {
+			ret[i].Peer = host.peerFor(&cPeers[i])
+		}
+	}
+	return ret
+}
+
+func (host *enetHost) ConnectedPeerCount() int {
+	if host.destroyed() {
+		return 0
+	}
+
+	return int(host.cHost.connectedPeers)
+}
@@ -0,0 +1,154 @@
+package enet
+
+// #include <enet/enet.h>
+import "C"
+import (
+	"time"
+)
+
+// PeerStats is a snapshot of the connection quality and traffic counters of a peer
+type PeerStats struct {
+	// RoundTripTime is the mean round trip time between sending a reliable packet and
+	// receiving its acknowledgement
+	RoundTripTime time.Duration
+
+	// RoundTripTimeVariance is the variance of the mean round trip time
+	RoundTripTimeVariance time.Duration
+
+	// LastRoundTripTime is the round trip time of the last reliable packet acknowledged
+	LastRoundTripTime time.Duration
+
+	// LowestRoundTripTime is the lowest round trip time measured in the current interval
+	LowestRoundTripTime time.Duration
+
+	// PacketLoss is the mean packet loss of reliable packets as a ratio between 0 and 1
+	PacketLoss float64
+
+	// PacketLossVariance is the variance of the mean packet loss as a ratio between 0 and 1
+	PacketLossVariance float64
+
+	// PacketsSent is the number of reliable packets sent in the current packet loss interval
+	PacketsSent uint32
+
+	// PacketsLost is the number of reliable packets lost in the current packet loss interval
+	PacketsLost uint32
+
+	// PacketThrottle is the current unreliable packet throttle as a ratio between 0 and 1,
+	// where 1 means no unreliable packets are being dropped
+	PacketThrottle float64
+
+	// MTU is the maximum transmission unit negotiated with the peer
+	MTU uint32
+
+	// WindowSize is the reliable window size negotiated with the peer
+	WindowSize uint32
+
+	// ReliableDataInTransit is the number of bytes of reliable data waiting to be acknowledged
+	ReliableDataInTransit uint32
+
+	// IncomingBandwidth is the downstream bandwidth of the peer in bytes per second, or 0
+	// if unlimited
+	IncomingBandwidth uint32
+
+	// OutgoingBandwidth is the upstream bandwidth of the peer in bytes per second, or 0
+	// if unlimited
+	OutgoingBandwidth uint32
+
+	// IncomingDataTotal is the number of bytes received from the peer in the current
+	// bandwidth throttle interval
+	IncomingDataTotal uint32
+
+	// OutgoingDataTotal is the number of bytes sent to the peer in the current bandwidth
+	// throttle interval
+	OutgoingDataTotal uint32
+}
+
+func millisecondsToDuration(ms C.enet_uint32) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+func (peer *enetPeer) Stats() PeerStats {
+	if peer.released() {
+		return PeerStats{}
+	}
+
+	p := peer.cPeer
+	return PeerStats{
+		RoundTripTime:         millisecondsToDuration(p.roundTripTime),
+		RoundTripTimeVariance: millisecondsToDuration(p.roundTripTimeVariance),
+		LastRoundTripTime:     millisecondsToDuration(p.lastRoundTripTime),
+		LowestRoundTripTime:   millisecondsToDuration(p.lowestRoundTripTime),
+		PacketLoss:            float64(p.packetLoss) / C.ENET_PEER_PACKET_LOSS_SCALE,
+		PacketLossVariance:    float64(p.packetLossVariance) / C.ENET_PEER_PACKET_LOSS_SCALE,
+		PacketsSent:           uint32(p.packetsSent),
+		PacketsLost:           uint32(p.packetsLost),
+		PacketThrottle:        float64(p.packetThrottle) / C.ENET_PEER_PACKET_THROTTLE_SCALE,
+		MTU:                   uint32(p.mtu),
+		WindowSize:            uint32(p.windowSize),
+		ReliableDataInTransit: uint32(p.reliableDataInTransit),
+		IncomingBandwidth:     uint32(p.incomingBandwidth),
+		OutgoingBandwidth:     uint32(p.outgoingBandwidth),
+		IncomingDataTotal:     uint32(p.incomingDataTotal),
+		OutgoingDataTotal:     uint32(p.outgoingDataTotal),
+	}
+}
+
+// HostStats is a snapshot of the traffic counters of a host
+type HostStats struct {
+	// TotalSentData is the number of bytes sent since the host was created or since the
+	// last call to Host.ResetStats
+	TotalSentData uint64
+
+	// TotalSentPackets is the number of UDP packets sent since the host was created or
+	// since the last call to Host.ResetStats
+	TotalSentPackets uint64
+
+	// TotalReceivedData is the number of bytes received since the host was created or
+	// since the last call to Host.ResetStats
+	TotalReceivedData uint64
+
+	// TotalReceivedPackets is the number of UDP packets received since the host was
+	// created or since the last call to Host.ResetStats
+	TotalReceivedPackets uint64
+
+	// ConnectedPeers is the number of peers currently connected to the host
+	ConnectedPeers int
+
+	// BandwidthLimitedPeers is the number of connected peers that have a bandwidth limit
+	BandwidthLimitedPeers int
+}
+
+// collectStats moves the 32-bit traffic counters of the C host into the 64-bit Go
+// counters and resets the C counters to 0, so they never overflow.
+func (host *enetHost) collectStats() {
+	h := host.cHost
+
+	host.stats.TotalSentData += uint64(h.totalSentData)
+	host.stats.TotalSentPackets += uint64(h.totalSentPackets)
+	host.stats.TotalReceivedData += uint64(h.totalReceivedData)
+	host.stats.TotalReceivedPackets += uint64(h.totalReceivedPackets)
+
+	h.totalSentData = 0
+	h.totalSentPackets = 0
+	h.totalReceivedData = 0
+	h.totalReceivedPackets = 0
+}
+
+func (host *enetHost) Stats() HostStats {
+	if host.destroyed() {
+		return host.stats
+	}
+
+	host.collectStats()
+
+	ret := host.stats
+	ret.ConnectedPeers = int(host.cHost.connectedPeers)
+	ret.BandwidthLimitedPeers = int(host.cHost.bandwidthLimitedPeers)
+	return ret
+}
+
+func (host *enetHost) ResetStats() HostStats {
+	ret := host.Stats()
+	host.stats = HostStats{}
+	return ret
+}
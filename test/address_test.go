@@ -0,0 +1,145 @@
+package enet_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/codecat/go-enet"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestAddressConversions(t *testing.T) {
+	addrPort := netip.MustParseAddrPort("192.168.1.2:1234")
+
+	addr, err := enet.AddressFromAddrPort(addrPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := addr.String(); s != "192.168.1.2:1234" {
+		t.Fatalf("expected ip:port, got %q", s)
+	}
+	if addr.AddrPort() != addrPort {
+		t.Fatalf("expected %s, got %s", addrPort, addr.AddrPort())
+	}
+
+	udpAddr := addr.UDPAddr()
+	if !udpAddr.IP.Equal(net.IPv4(192, 168, 1, 2)) || udpAddr.Port != 1234 {
+		t.Fatalf("expected UDP address of %s, got %s", addrPort, udpAddr)
+	}
+
+	addr, err = enet.AddressFromUDPAddr(udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.AddrPort() != addrPort {
+		t.Fatalf("expected %s, got %s", addrPort, addr.AddrPort())
+	}
+
+	if s := enet.NewListenAddress(8095).String(); s != "0.0.0.0:8095" {
+		t.Fatalf("expected listen address on any host, got %q", s)
+	}
+
+	// Enet only supports IPv4.
+	if _, err := enet.AddressFromAddrPort(netip.MustParseAddrPort("[::1]:1234")); !errors.Is(err, enet.ErrInvalidAddress) {
+		t.Fatalf("expected ErrInvalidAddress, got %v", err)
+	}
+}
+
+func TestNewAddressResolveFailed(t *testing.T) {
+	if _, err := enet.NewAddress("host.invalid", 1234); !errors.Is(err, enet.ErrResolveFailed) {
+		t.Fatalf("expected ErrResolveFailed, got %v", err)
+	}
+}
+
+func TestConnectUDPAddr(t *testing.T) {
+	host, err := enet.NewHost(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: int(getFreePort())}, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Destroy()
+
+	serverPort := getFreePort()
+	peer, err := host.Connect(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: int(serverPort)}, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := peer.GetAddress().String(); s != fmt.Sprintf("127.0.0.1:%d", serverPort) {
+		t.Fatalf("expected peer on port %d, got %s", serverPort, s)
+	}
+
+	if _, err := host.Connect(&net.UDPAddr{IP: net.IPv6loopback, Port: 1234}, 1, 0); !errors.Is(err, enet.ErrInvalidAddress) {
+		t.Fatalf("expected ErrInvalidAddress, got %v", err)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	addr, err := enet.ParseAddress("10.0.0.1:8095")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := addr.String(); s != "10.0.0.1:8095" {
+		t.Fatalf("expected 10.0.0.1:8095, got %q", s)
+	}
+
+	// Hostnames are never resolved.
+	for _, s := range []string{"localhost:8095", "10.0.0.1", "10.0.0.1:port", "[::1]:8095"} {
+		if _, err := enet.ParseAddress(s); !errors.Is(err, enet.ErrInvalidAddress) {
+			t.Fatalf("expected %q to be rejected with ErrInvalidAddress, got %v", s, err)
+		}
+	}
+}
+
+func TestResolveAddress(t *testing.T) {
+	addr, err := enet.ResolveAddress(context.Background(), nil, "127.0.0.1", 8095)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := addr.String(); s != "127.0.0.1:8095" {
+		t.Fatalf("expected 127.0.0.1:8095, got %q", s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := enet.ResolveAddress(ctx, nil, "example.com", 8095); !errors.Is(err, enet.ErrResolveFailed) {
+		t.Fatalf("expected cancelled lookup to fail with ErrResolveFailed, got %v", err)
+	}
+}
+
+func TestAddressHostname(t *testing.T) {
+	addr, err := enet.ParseAddress("127.0.0.1:8095")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostname, err := addr.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostname == "" {
+		t.Fatal("expected a hostname or the IP of the address")
+	}
+}
+
+func TestListenUDPAddr(t *testing.T) {
+	for _, udpAddr := range []*net.UDPAddr{
+		{Port: 8095},
+		{IP: net.IPv4zero, Port: 8095},
+		{IP: net.IPv6unspecified, Port: 8095},
+	} {
+		addr, err := enet.AddressFromUDPAddr(udpAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := addr.String(); s != "0.0.0.0:8095" {
+			t.Fatalf("expected %s to listen on any host, got %q", udpAddr, s)
+		}
+	}
+
+	host, err := enet.NewHost(&net.UDPAddr{Port: int(getFreePort())}, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host.Destroy()
+}
@@ -0,0 +1,103 @@
+package enet_test
+
+import (
+	"github.com/codecat/go-enet"
+	"testing"
+	"time"
+)
+
+func TestCRC32(t *testing.T) {
+	whole := enet.CRC32([]byte("hello world"))
+
+	if whole == enet.CRC32([]byte("hello world!")) {
+		t.Fatal("expected different data to have a different checksum")
+	}
+
+	// The checksum covers the concatenation of all buffers, however they are split.
+	split := enet.CRC32([]byte("hello"), nil, []byte(" world"))
+	if split != whole {
+		t.Fatalf("expected split buffers to have checksum %08x, got %08x", whole, split)
+	}
+}
+
+// sendChecked sends a packet from the client to the server of connected hosts, and waits
+// for the server to receive it.
+func sendChecked(t *testing.T, server, client enet.Host, peer enet.Peer) {
+	t.Helper()
+
+	if err := peer.SendString("hello", 0, enet.PacketFlagReliable); err != nil {
+		t.Fatal(err)
+	}
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		return ev.GetType() == enet.EventReceive && string(ev.GetPacket().GetData()) == "hello"
+	})
+}
+
+func TestHostEnableCRC32Checksum(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, func(server, client enet.Host) {
+		server.EnableCRC32Checksum()
+		client.EnableCRC32Checksum()
+	})
+
+	sendChecked(t, server, client, peer)
+}
+
+func TestHostSetChecksum(t *testing.T) {
+	var serverCalls, clientCalls int
+	server, client, peer := connectHosts(t, 1, func(server, client enet.Host) {
+		server.SetChecksum(func(buffers [][]byte) uint32 {
+			serverCalls++
+			return enet.CRC32(buffers...)
+		})
+		client.SetChecksum(func(buffers [][]byte) uint32 {
+			clientCalls++
+			return enet.CRC32(buffers...)
+		})
+	})
+
+	sendChecked(t, server, client, peer)
+
+	if serverCalls == 0 || clientCalls == 0 {
+		t.Fatalf("expected both checksum functions to be called, got %d and %d calls", serverCalls, clientCalls)
+	}
+}
+
+func TestHostChecksumMismatch(t *testing.T) {
+	serverPort := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(serverPort), 1, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Destroy()
+	server.EnableCRC32Checksum()
+
+	client, err := enet.NewHost(nil, 1, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Destroy()
+	client.SetChecksum(func(buffers [][]byte) uint32 {
+		return ^enet.CRC32(buffers...)
+	})
+
+	if _, err := client.Connect(localAddress(t, serverPort), 1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server drops every packet of the client, so it never sees the connection.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := client.Service(1); err != nil {
+			t.Fatal(err)
+		}
+
+		ev, err := server.Service(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.GetType() != enet.EventNone {
+			t.Fatalf("expected packets with a wrong checksum to be dropped, got event %v", ev.GetType())
+		}
+	}
+}
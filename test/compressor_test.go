@@ -0,0 +1,135 @@
+package enet_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"github.com/codecat/go-enet"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlateCompressor(t *testing.T) {
+	snapshot := bytes.Repeat([]byte("position=1.0,2.0,3.0;velocity=0.0,0.0,0.0;"), 8)
+
+	plain, err := enet.NewFlateCompressor(flate.BestSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dict, err := enet.NewFlateDictCompressor(flate.BestSpeed, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, compressor := range map[string]enet.Compressor{"plain": plain, "dict": dict} {
+		t.Run(name, func(t *testing.T) {
+			// Packets are handed to the compressor in multiple buffers.
+			in := [][]byte{snapshot[:100], snapshot[100:]}
+
+			compressed := make([]byte, len(snapshot))
+			n := compressor.Compress(in, len(snapshot), compressed)
+			if n == 0 || n >= len(snapshot) {
+				t.Fatalf("expected snapshot to compress to less than %d bytes, got %d", len(snapshot), n)
+			}
+
+			decompressed := make([]byte, len(snapshot))
+			m := compressor.Decompress(compressed[:n], decompressed)
+			if !bytes.Equal(decompressed[:m], snapshot) {
+				t.Fatalf("expected decompressed data to match the snapshot")
+			}
+
+			// Output that doesn't fit must fail rather than be truncated.
+			if compressor.Decompress(compressed[:n], make([]byte, 10)) != 0 {
+				t.Fatal("expected decompressing into a small buffer to fail")
+			}
+			if compressor.Compress(in, len(snapshot), make([]byte, 2)) != 0 {
+				t.Fatal("expected compressing into a small buffer to fail")
+			}
+		})
+	}
+}
+
+// compressorCalls counts the calls enet makes to a countingCompressor.
+type compressorCalls struct {
+	compress   int
+	decompress int
+
+	// released is set once the compressor has been garbage collected.
+	released atomic.Bool
+}
+
+// countingCompressor wraps a compressor to count the calls made to it.
+type countingCompressor struct {
+	enet.Compressor
+	calls *compressorCalls
+}
+
+func (compressor *countingCompressor) Compress(in [][]byte, limit int, out []byte) int {
+	compressor.calls.compress++
+	return compressor.Compressor.Compress(in, limit, out)
+}
+
+func (compressor *countingCompressor) Decompress(in []byte, out []byte) int {
+	compressor.calls.decompress++
+	return compressor.Compressor.Decompress(in, out)
+}
+
+// newCountingCompressor creates a flate compressor that counts its calls, and reports
+// when it has been garbage collected.
+func newCountingCompressor(t *testing.T) (*countingCompressor, *compressorCalls) {
+	flateCompressor, err := enet.NewFlateCompressor(flate.BestSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := &compressorCalls{}
+	compressor := &countingCompressor{Compressor: flateCompressor, calls: calls}
+	runtime.SetFinalizer(compressor, func(*countingCompressor) {
+		calls.released.Store(true)
+	})
+	return compressor, calls
+}
+
+func TestHostCompressor(t *testing.T) {
+	serverCompressor, serverCalls := newCountingCompressor(t)
+	clientCompressor, clientCalls := newCountingCompressor(t)
+
+	server, client, peer := connectHosts(t, 1, func(server, client enet.Host) {
+		server.SetCompressor(serverCompressor)
+		client.SetCompressor(clientCompressor)
+	})
+	serverCompressor, clientCompressor = nil, nil
+
+	snapshot := bytes.Repeat([]byte("position=1.0,2.0,3.0;velocity=0.0,0.0,0.0;"), 8)
+	if err := peer.SendBytes(snapshot, 0, enet.PacketFlagReliable); err != nil {
+		t.Fatal(err)
+	}
+
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		if ev.GetType() != enet.EventReceive {
+			return false
+		}
+		if !bytes.Equal(ev.GetPacket().GetData(), snapshot) {
+			t.Fatal("expected the packet to survive compression")
+		}
+		return true
+	})
+
+	if clientCalls.compress == 0 || serverCalls.decompress == 0 {
+		t.Fatalf("expected packets to go through the compressors, got client %+v and server %+v", clientCalls, serverCalls)
+	}
+
+	// Removing the compressor releases it, so it can be garbage collected.
+	server.SetCompressor(nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !serverCalls.released.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the removed compressor to be released")
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
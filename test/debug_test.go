@@ -0,0 +1,114 @@
+//go:build enetdebug
+
+package enet_test
+
+import (
+	"bytes"
+	"github.com/codecat/go-enet"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugReportsLeakedPackets(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	serverPort := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(serverPort), 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Destroy()
+
+	peer, err := client.Connect(localAddress(t, serverPort), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Service both hosts until the server receives a packet, which is never destroyed.
+	sent := false
+	for received := false; !received; {
+		if _, err := client.Service(1); err != nil {
+			t.Fatal(err)
+		}
+		if !sent && peer.SendString("leak", 0, enet.PacketFlagReliable) == nil {
+			sent = true
+		}
+
+		ev, err := server.Service(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = ev.GetType() == enet.EventReceive
+	}
+
+	server.Destroy()
+
+	if !strings.Contains(output.String(), "was never destroyed") {
+		t.Fatalf("expected leaked packet to be reported, got %q", output.String())
+	}
+}
+
+func TestDebugForwardedPacketsNotReported(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	server, client, peer := connectHosts(t, 1, nil)
+
+	if err := peer.SendString("relay", 0, enet.PacketFlagReliable); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server sends the received packet back, which gives it to enet.
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		if ev.GetType() != enet.EventReceive {
+			return false
+		}
+		if err := ev.GetPeer().SendPacket(ev.GetPacket(), 0); err != nil {
+			t.Fatal(err)
+		}
+		return true
+	})
+
+	// Service both hosts until the client has the packet, so enet frees it once the
+	// client acknowledges it.
+	deadline := time.Now().Add(5 * time.Second)
+	for received := false; !received; {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the forwarded packet")
+		}
+		if _, err := server.Service(1); err != nil {
+			t.Fatal(err)
+		}
+
+		ev, err := client.Service(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.GetType() == enet.EventReceive {
+			received = true
+			ev.GetPacket().Destroy()
+		}
+	}
+	for i := 0; i < 10; i++ {
+		server.Service(1)
+		client.Service(1)
+	}
+
+	server.Destroy()
+
+	if strings.Contains(output.String(), "was never destroyed") {
+		t.Fatalf("expected forwarded packet not to be reported, got %q", output.String())
+	}
+}
@@ -16,7 +16,9 @@ func Example_clientServer() {
 	fmt.Printf("enet version: %s\n", enet.LinkedVersion())
 
 	// Initialize enet
-	enet.Initialize()
+	if err := enet.Initialize(); err != nil {
+		log.Fatal(err)
+	}
 
 	// Make our server.
 	server, err := enet.NewHost(enet.NewListenAddress(port), 32, 1, 0, 0)
@@ -31,7 +33,10 @@ func Example_clientServer() {
 	// Setup our server handling running in a separate goroutine.
 	go func() {
 		for true {
-			ev := server.Service(10)
+			ev, err := server.Service(10)
+			if err != nil {
+				log.Fatal(err)
+			}
 
 			switch ev.GetType() {
 			case enet.EventConnect:
@@ -61,7 +66,11 @@ func Example_clientServer() {
 	}
 
 	// Connect to the server.
-	peer, err := client.Connect(enet.NewAddress("localhost", port), 1, 0)
+	addr, err := enet.NewAddress("localhost", port)
+	if err != nil {
+		log.Fatal(err)
+	}
+	peer, err := client.Connect(addr, 1, 0)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -69,7 +78,10 @@ func Example_clientServer() {
 	// Keep checking the client until we get a response from the server.
 	done := false
 	for !done {
-		ev := client.Service(10)
+		ev, err := client.Service(10)
+		if err != nil {
+			log.Fatal(err)
+		}
 
 		switch ev.GetType() {
 		case enet.EventReceive:
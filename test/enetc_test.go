@@ -0,0 +1,33 @@
+package enet_test
+
+import (
+	"errors"
+	"github.com/codecat/go-enet"
+	"testing"
+)
+
+func TestInitializeReferenceCount(t *testing.T) {
+	// TestMain has already initialized enet, so this is a second user of the library.
+	if err := enet.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deinitializing here must not pull enet out from under the other user.
+	if err := enet.Deinitialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := host.Service(0); err != nil {
+		t.Fatalf("expected host to still work after balanced deinitialize: %s", err)
+	}
+	host.Destroy()
+
+	if err := enet.InitializeWithOptions(enet.InitializeOptions{}); !errors.Is(err, enet.ErrAlreadyInitialized) {
+		t.Fatalf("expected %v, got %v", enet.ErrAlreadyInitialized, err)
+	}
+}
@@ -0,0 +1,256 @@
+package enet_test
+
+import (
+	"errors"
+	"github.com/codecat/go-enet"
+	"testing"
+	"time"
+)
+
+// connectHosts creates a server and a client connected to it, by servicing both hosts
+// until the connection is established. Both hosts are serviced on the calling goroutine.
+// If configure is not nil, it is called with both hosts before connecting.
+func connectHosts(t *testing.T, channelCount int, configure func(server, client enet.Host)) (server, client enet.Host, peer enet.Peer) {
+	serverPort := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(serverPort), 1, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Destroy() })
+
+	client, err = enet.NewHost(nil, 1, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Destroy() })
+
+	if configure != nil {
+		configure(server, client)
+	}
+
+	peer, err = client.Connect(localAddress(t, serverPort), channelCount, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		return ev.GetType() == enet.EventConnect
+	})
+	return server, client, peer
+}
+
+// serviceUntil services the server and client until done returns true for an event of
+// the server, or fails the test after 5 seconds.
+func serviceUntil(t *testing.T, server, client enet.Host, done func(ev enet.Event) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := client.Service(1); err != nil {
+			t.Fatal(err)
+		}
+
+		ev, err := server.Service(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.GetType() == enet.EventReceive {
+			defer ev.GetPacket().Destroy()
+		}
+		if done(ev) {
+			return
+		}
+	}
+	t.Fatal("timed out servicing hosts")
+}
+
+func TestHostSetBandwidthLimit(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+
+	// The server tells its peers about its new bandwidth limits.
+	server.SetBandwidthLimit(1000, 2000)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for peer.Stats().IncomingBandwidth != 1000 || peer.Stats().OutgoingBandwidth != 2000 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected client to learn the bandwidth of the server, got %+v", peer.Stats())
+		}
+		serviceUntil(t, server, client, func(enet.Event) bool { return true })
+	}
+}
+
+func TestHostSetChannelLimit(t *testing.T) {
+	// The client asks for 2 channels, but only gets 1.
+	_, _, peer := connectHosts(t, 2, func(server, _ enet.Host) {
+		server.SetChannelLimit(1)
+	})
+
+	if err := peer.SendString("hello", 1, enet.PacketFlagReliable); !errors.Is(err, enet.ErrInvalidChannel) {
+		t.Fatalf("expected %v, got %v", enet.ErrInvalidChannel, err)
+	}
+	if err := peer.SendString("hello", 0, enet.PacketFlagReliable); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHostFlush(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+
+	if err := peer.SendString("hello", 0, enet.PacketFlagReliable); err != nil {
+		t.Fatal(err)
+	}
+	client.Flush()
+
+	// Only the server is serviced from here on, so the packet must have been sent by Flush.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the flushed packet")
+		}
+
+		ev, err := server.Service(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.GetType() == enet.EventReceive {
+			ev.GetPacket().Destroy()
+			return
+		}
+	}
+}
+
+func TestHostCheckEvents(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+
+	if err := peer.SendString("hello", 0, enet.PacketFlagReliable); err != nil {
+		t.Fatal(err)
+	}
+	client.Flush()
+	time.Sleep(50 * time.Millisecond)
+
+	// CheckEvents doesn't read from the socket, so the packet isn't received yet.
+	ev, err := server.CheckEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.GetType() != enet.EventNone {
+		t.Fatalf("expected no event, got %d", ev.GetType())
+	}
+
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		return ev.GetType() == enet.EventReceive
+	})
+}
+
+func TestHostServiceAll(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+
+	for i := 0; i < 3; i++ {
+		if err := peer.SendString("hello", 0, enet.PacketFlagReliable); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Flush()
+	time.Sleep(50 * time.Millisecond)
+
+	// All packets arrived in the same datagram, so they're returned together.
+	events, err := server.ServiceAll(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	for _, ev := range events {
+		if ev.GetType() != enet.EventReceive {
+			t.Fatalf("expected receive event, got %d", ev.GetType())
+		}
+		ev.GetPacket().Destroy()
+	}
+
+	if events, err := server.ServiceAll(0); err != nil || len(events) != 0 {
+		t.Fatalf("expected no more events, got %d (%v)", len(events), err)
+	}
+}
+
+func TestHostServiceAllDisconnects(t *testing.T) {
+	serverPort := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(serverPort), 2, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Destroy()
+
+	// Connect two clients, and attach a value to their peers on the server.
+	var clients []enet.Host
+	var peers []enet.Peer
+	for i := 0; i < 2; i++ {
+		client, err := enet.NewHost(nil, 1, 1, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Destroy()
+
+		peer, err := client.Connect(localAddress(t, serverPort), 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, client)
+		peers = append(peers, peer)
+	}
+
+	connected := 0
+	deadline := time.Now().Add(5 * time.Second)
+	for connected < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for clients to connect")
+		}
+		for _, client := range clients {
+			if _, err := client.Service(1); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ev, err := server.Service(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.GetType() == enet.EventConnect {
+			ev.GetPeer().SetValue(connected)
+			connected++
+		}
+	}
+
+	// Disconnect both clients at once, so their disconnect events are returned together.
+	for i, peer := range peers {
+		peer.Disconnect(0)
+		clients[i].Flush()
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	disconnected := 0
+	deadline = time.Now().Add(5 * time.Second)
+	for disconnected < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for clients to disconnect")
+		}
+
+		events, err := server.ServiceAll(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The values of all peers are still available after ServiceAll returns.
+		for _, ev := range events {
+			if ev.GetType() != enet.EventDisconnect {
+				continue
+			}
+			if _, ok := ev.GetPeer().Value().(int); !ok {
+				t.Fatal("expected peer value to be available during the disconnect event")
+			}
+			disconnected++
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package enet_test
+
+import (
+	"github.com/codecat/go-enet"
+	"testing"
+)
+
+func TestHostConfigValidate(t *testing.T) {
+	if err := enet.NewHostConfig().Validate(); err != nil {
+		t.Fatalf("expected default config to be valid, got %v", err)
+	}
+
+	invalid := []enet.HostOption{
+		enet.WithPeerCount(0),
+		enet.WithPeerCount(enet.MaximumPeerCount + 1),
+		enet.WithChannelLimit(enet.MaximumChannelCount + 1),
+		enet.WithDuplicatePeers(0),
+		enet.WithMaximumPacketSize(0),
+		enet.WithMaximumPacketSize(enet.MaximumPacketSizeLimit + 1),
+		enet.WithMaximumWaitingData(0),
+		enet.WithMaximumWaitingData(enet.MaximumPacketSizeLimit + 1),
+		enet.WithMTU(enet.MinimumMTU - 1),
+		enet.WithMTU(enet.MaximumMTU + 1),
+	}
+	for i, option := range invalid {
+		if _, err := enet.NewHostFromConfig(enet.NewHostConfig(option)); err == nil {
+			t.Fatalf("expected invalid option %d to be rejected", i)
+		}
+	}
+
+	// NewHost clamps the channel limit like enet does, instead of rejecting it.
+	host, err := enet.NewHost(nil, 1, enet.MaximumChannelCount+1, 0, 0)
+	if err != nil {
+		t.Fatalf("expected NewHost to clamp the channel limit, got %v", err)
+	}
+	host.Destroy()
+}
+
+func TestHostConfig(t *testing.T) {
+	host, err := enet.NewHostFromConfig(enet.NewHostConfig(
+		enet.WithPeerCount(4),
+		enet.WithDuplicatePeers(2),
+		enet.WithMaximumPacketSize(1024),
+		enet.WithMaximumWaitingData(4096),
+		enet.WithMTU(enet.MinimumMTU),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Destroy()
+
+	if n := host.DuplicatePeers(); n != 2 {
+		t.Fatalf("expected 2 duplicate peers, got %d", n)
+	}
+	if n := host.MaximumPacketSize(); n != 1024 {
+		t.Fatalf("expected maximum packet size of 1024, got %d", n)
+	}
+	if n := host.MaximumWaitingData(); n != 4096 {
+		t.Fatalf("expected maximum waiting data of 4096, got %d", n)
+	}
+	if n := host.MTU(); n != enet.MinimumMTU {
+		t.Fatalf("expected mtu of %d, got %d", enet.MinimumMTU, n)
+	}
+
+	if err := host.SetMTU(enet.MaximumMTU + 1); err == nil {
+		t.Fatal("expected mtu above the protocol limit to be rejected")
+	}
+	if err := host.SetMaximumPacketSize(2048); err != nil {
+		t.Fatal(err)
+	}
+	if n := host.MaximumPacketSize(); n != 2048 {
+		t.Fatalf("expected maximum packet size of 2048, got %d", n)
+	}
+}
@@ -0,0 +1,52 @@
+package enet_test
+
+import (
+	"fmt"
+	"github.com/codecat/go-enet"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestInterceptor(t *testing.T) {
+	port := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(port), 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Answer server browser queries on the same port as enet.
+	server.SetInterceptor(func(from enet.Address, data []byte) enet.InterceptResult {
+		if string(data) != "query" {
+			return enet.InterceptIgnore
+		}
+		if err := server.SendRaw(from, []byte("players=0")); err != nil {
+			t.Error(err)
+		}
+		return enet.InterceptHandled
+	})
+
+	runHost(t, server, nil)
+
+	conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("query")); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply := make([]byte, 64)
+	n, err := conn.Read(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(reply[:n]) != "players=0" {
+		t.Fatalf("expected query reply, got %q", reply[:n])
+	}
+}
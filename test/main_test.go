@@ -0,0 +1,25 @@
+package enet_test
+
+import (
+	"github.com/codecat/go-enet"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// The memory tests run themselves in a separate process that initializes enet with
+	// its own options.
+	if os.Getenv("GO_ENET_MEMORY_TEST") == "1" {
+		os.Exit(m.Run())
+	}
+
+	if err := enet.Initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+
+	enet.Deinitialize()
+	os.Exit(code)
+}
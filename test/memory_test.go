@@ -0,0 +1,119 @@
+package enet_test
+
+import (
+	"errors"
+	"github.com/codecat/go-enet"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// runInSeparateProcess runs the calling test in a separate process where enet hasn't
+// been initialized yet, and returns true if the test should continue there.
+func runInSeparateProcess(t *testing.T) bool {
+	if os.Getenv("GO_ENET_MEMORY_TEST") == "1" {
+		return true
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$", "-test.v")
+	cmd.Env = append(os.Environ(), "GO_ENET_MEMORY_TEST=1")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("memory test failed: %s\n%s", err, output)
+	}
+	return false
+}
+
+func TestMemoryLimit(t *testing.T) {
+	// The allocator can only be replaced before enet allocates anything, so run this test
+	// in a separate process.
+	if !runInSeparateProcess(t) {
+		return
+	}
+
+	failures := 0
+	err := enet.InitializeWithOptions(enet.InitializeOptions{
+		MemoryLimit: 4 * 1024 * 1024,
+		OnNoMemory: func() {
+			failures++
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enet.Deinitialize()
+
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usage := enet.GetMemoryUsage()
+	if usage.Bytes == 0 || usage.Allocations == 0 {
+		t.Fatalf("expected host allocations to be tracked, got %+v", usage)
+	}
+
+	// A packet larger than the limit can't be allocated, but mustn't abort the process.
+	if _, err := enet.NewPacket(make([]byte, 8*1024*1024), enet.PacketFlagReliable); err == nil {
+		t.Fatal("expected packet larger than the memory limit to fail")
+	}
+	if failures == 0 {
+		t.Fatal("expected OnNoMemory to be called")
+	}
+
+	host.Destroy()
+
+	if usage := enet.GetMemoryUsage(); usage.Bytes != 0 || usage.Allocations != 0 {
+		t.Fatalf("expected all memory to be freed after destroying the host, got %+v", usage)
+	}
+}
+
+func TestMemoryHooksAfterAllocating(t *testing.T) {
+	if !runInSeparateProcess(t) {
+		return
+	}
+
+	// A packet created before initializing was allocated without the hooks, so they can't
+	// be installed anymore.
+	packet, err := enet.NewPacket([]byte("hello"), enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer packet.Destroy()
+
+	err = enet.InitializeWithOptions(enet.InitializeOptions{})
+	if !errors.Is(err, enet.ErrAllocatedWithoutHooks) {
+		t.Fatalf("expected %v, got %v", enet.ErrAllocatedWithoutHooks, err)
+	}
+}
+
+func TestFailedSendFreesPacket(t *testing.T) {
+	// Memory usage is only tracked with the memory hooks.
+	if !runInSeparateProcess(t) {
+		return
+	}
+
+	if err := enet.InitializeWithOptions(enet.InitializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	defer enet.Deinitialize()
+
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Destroy()
+
+	peer, err := host.Connect(localAddress(t, getFreePort()), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The peer isn't connected yet, so the packet created by SendBytes must be freed.
+	before := enet.GetMemoryUsage()
+	if err := peer.SendBytes([]byte("hello"), 0, enet.PacketFlagReliable); !errors.Is(err, enet.ErrPeerNotConnected) {
+		t.Fatalf("expected %v, got %v", enet.ErrPeerNotConnected, err)
+	}
+	if after := enet.GetMemoryUsage(); after != before {
+		t.Fatalf("expected failed send to free its packet, usage went from %+v to %+v", before, after)
+	}
+}
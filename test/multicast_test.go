@@ -0,0 +1,118 @@
+package enet_test
+
+import (
+	"errors"
+	"github.com/codecat/go-enet"
+	"testing"
+	"time"
+)
+
+func TestMulticast(t *testing.T) {
+	port := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(port), 2, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Destroy()
+
+	// Connect two clients, which report the packets they receive on their own channel.
+	received := make([]chan string, 2)
+	for i := range received {
+		ch := make(chan string, 4)
+		received[i] = ch
+
+		connectClient(t, port, 1, func(ev enet.Event) {
+			if ev.GetType() == enet.EventReceive {
+				ch <- string(ev.GetPacket().GetData())
+			}
+		})
+	}
+
+	for server.ConnectedPeerCount() < 2 {
+		if _, err := server.Service(1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	peers := server.Peers()
+
+	packet, _ := enet.NewPacket([]byte("first"), enet.PacketFlagReliable)
+	if err := server.Multicast(packet, 0, peers[:1]); err != nil {
+		t.Fatal(err)
+	}
+	packet, _ = enet.NewPacket([]byte("second"), enet.PacketFlagReliable)
+	if err := server.BroadcastExcept(packet, 0, peers[0]); err != nil {
+		t.Fatal(err)
+	}
+	server.Flush()
+
+	// Each client receives exactly one of the packets, depending on which peer it is.
+	got := map[string]bool{}
+	for i, ch := range received {
+		select {
+		case data := <-ch:
+			got[data] = true
+		case <-time.After(time.Second):
+			t.Fatalf("client %d: timed out waiting for a packet", i)
+		}
+	}
+	if !got["first"] || !got["second"] {
+		t.Fatalf("expected each client to receive one packet, got %v", got)
+	}
+
+	// A packet that no peer takes is destroyed.
+	packet, _ = enet.NewPacket([]byte("third"), enet.PacketFlagReliable)
+	if err := server.BroadcastExcept(packet, 0, peers...); !errors.Is(err, enet.ErrNoPeersConnected) {
+		t.Fatalf("expected ErrNoPeersConnected, got %v", err)
+	}
+	if err := packet.Destroy(); !errors.Is(err, enet.ErrPacketDestroyed) {
+		t.Fatalf("expected packet to be destroyed, got %v", err)
+	}
+}
+
+func TestMulticastRejected(t *testing.T) {
+	_, client, peer := connectHosts(t, 1, nil)
+	if err := client.SetMaximumPacketSize(4); err != nil {
+		t.Fatal(err)
+	}
+
+	// The peer is connected, but can't take the packet, so the reason is returned.
+	packet, _ := enet.NewPacket([]byte("too large"), enet.PacketFlagReliable)
+	if err := client.Multicast(packet, 0, []enet.Peer{peer}); !errors.Is(err, enet.ErrPacketTooLarge) {
+		t.Fatalf("expected %v, got %v", enet.ErrPacketTooLarge, err)
+	}
+	if err := packet.Destroy(); !errors.Is(err, enet.ErrPacketDestroyed) {
+		t.Fatalf("expected packet to be destroyed, got %v", err)
+	}
+
+	if err := client.BroadcastString("too large", 0, enet.PacketFlagReliable); !errors.Is(err, enet.ErrPacketTooLarge) {
+		t.Fatalf("expected %v, got %v", enet.ErrPacketTooLarge, err)
+	}
+	if err := client.BroadcastString("ok", 1, enet.PacketFlagReliable); !errors.Is(err, enet.ErrInvalidChannel) {
+		t.Fatalf("expected %v, got %v", enet.ErrInvalidChannel, err)
+	}
+}
+
+func TestMulticastDuplicatePeers(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+
+	packet, _ := enet.NewPacket([]byte("once"), enet.PacketFlagReliable)
+	if err := client.Multicast(packet, 0, []enet.Peer{peer, peer}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Service the hosts for a while, to give a duplicate time to arrive.
+	received := 0
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		serviceUntil(t, server, client, func(ev enet.Event) bool {
+			if ev.GetType() == enet.EventReceive {
+				received++
+			}
+			return true
+		})
+	}
+	if received != 1 {
+		t.Fatalf("expected the packet to be received once, got %d", received)
+	}
+}
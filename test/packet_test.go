@@ -0,0 +1,204 @@
+package enet_test
+
+import (
+	"errors"
+	"github.com/codecat/go-enet"
+	"testing"
+	"time"
+)
+
+func TestPacketInPlace(t *testing.T) {
+	packet, err := enet.NewPacketSize(5, enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer packet.Destroy()
+
+	// Write the data straight into the packet.
+	copy(packet.Bytes(), "hello")
+	if data := string(packet.GetData()); data != "hello" {
+		t.Fatalf("expected packet data to be hello, got %q", data)
+	}
+
+	if err := packet.Resize(11); err != nil {
+		t.Fatal(err)
+	}
+	copy(packet.Bytes()[5:], " world")
+	if data := string(packet.Bytes()); data != "hello world" {
+		t.Fatalf("expected resized packet data to be hello world, got %q", data)
+	}
+
+	if err := packet.Resize(5); err != nil {
+		t.Fatal(err)
+	}
+	if data := string(packet.Bytes()); data != "hello" {
+		t.Fatalf("expected shrunk packet data to be hello, got %q", data)
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	pool, err := enet.NewBufferPool(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	packet, err := pool.NewPacket(5, enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if packet.GetFlags()&enet.PacketFlagNoAllocate == 0 {
+		t.Fatal("expected pooled packet to have PacketFlagNoAllocate")
+	}
+
+	copy(packet.Bytes(), "hello")
+	buffer := &packet.Bytes()[0]
+
+	if _, err := pool.NewPacket(65, 0); err == nil {
+		t.Fatal("expected packet larger than the pool buffers to be rejected")
+	}
+	if err := packet.Resize(65); err == nil {
+		t.Fatal("expected resizing beyond the pool buffer to be rejected")
+	}
+
+	freed := false
+	if err := packet.SetFreeCallback(func() { freed = true }); err != nil {
+		t.Fatal(err)
+	}
+
+	packet.Destroy()
+	if !freed {
+		t.Fatal("expected free callback to be called when the packet is destroyed")
+	}
+
+	// The buffer of the destroyed packet is reused for the next one.
+	packet, err = pool.NewPacket(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer packet.Destroy()
+
+	if &packet.Bytes()[0] != buffer {
+		t.Fatal("expected the pool to reuse the buffer of the destroyed packet")
+	}
+}
+
+func TestPacketSent(t *testing.T) {
+	_, events := createServerClient(t)
+
+	// Wait for the server to accept the connection.
+	ev, _ := events.Next()
+
+	packet, err := enet.NewPacket([]byte("hello"), enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ev.GetPeer().SendPacket(packet, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enet owns the packet now, and may destroy it at any time.
+	if err := packet.Destroy(); !errors.Is(err, enet.ErrPacketSent) {
+		t.Fatalf("expected %v, got %v", enet.ErrPacketSent, err)
+	}
+	if data := packet.GetData(); data != nil {
+		t.Fatalf("expected no data for a sent packet, got %q", data)
+	}
+	if err := packet.Resize(1); !errors.Is(err, enet.ErrPacketSent) {
+		t.Fatalf("expected %v, got %v", enet.ErrPacketSent, err)
+	}
+}
+
+func TestPacketResend(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+
+	packet, err := enet.NewPacket([]byte("hello"), enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	freed := false
+	if err := packet.SetFreeCallback(func() { freed = true }); err != nil {
+		t.Fatal(err)
+	}
+
+	// A sent packet may be sent again until enet destroys it.
+	for i := 0; i < 2; i++ {
+		if err := peer.SendPacket(packet, 0); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	received := 0
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		if ev.GetType() == enet.EventReceive && string(ev.GetPacket().GetData()) == "hello" {
+			received++
+		}
+		return received == 2
+	})
+
+	// Enet destroys the packet once the server has acknowledged both sends.
+	deadline := time.Now().Add(5 * time.Second)
+	for !freed {
+		if time.Now().After(deadline) {
+			t.Fatal("expected enet to destroy the packet")
+		}
+		serviceUntil(t, server, client, func(enet.Event) bool { return true })
+	}
+	if err := peer.SendPacket(packet, 0); !errors.Is(err, enet.ErrPacketDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrPacketDestroyed, err)
+	}
+}
+
+func TestBroadcastOnDestroyedHost(t *testing.T) {
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host.Destroy()
+
+	if err := host.BroadcastString("hello", 0, enet.PacketFlagReliable); !errors.Is(err, enet.ErrHostDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrHostDestroyed, err)
+	}
+
+	// The caller keeps ownership of a packet the host couldn't take.
+	packet, err := enet.NewPacket([]byte("hello"), enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := host.BroadcastPacket(packet, 0); !errors.Is(err, enet.ErrHostDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrHostDestroyed, err)
+	}
+	if err := packet.Destroy(); err != nil {
+		t.Fatalf("expected packet to still be owned by the caller, got %v", err)
+	}
+}
+
+func TestBroadcastWithoutPeers(t *testing.T) {
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Destroy()
+
+	packet, err := enet.NewPacket([]byte("hello"), enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	freed := false
+	if err := packet.SetFreeCallback(func() { freed = true }); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody takes the packet, so it is freed right away.
+	if err := host.BroadcastPacket(packet, 0); !errors.Is(err, enet.ErrNoPeersConnected) {
+		t.Fatalf("expected %v, got %v", enet.ErrNoPeersConnected, err)
+	}
+	if !freed {
+		t.Fatal("expected packet to be freed")
+	}
+	if err := packet.Destroy(); !errors.Is(err, enet.ErrPacketDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrPacketDestroyed, err)
+	}
+}
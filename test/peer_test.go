@@ -1,6 +1,8 @@
 package enet_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/codecat/go-enet"
 	"os"
@@ -9,17 +11,18 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestPeerData(t *testing.T) {
 	testData := []byte{0x1, 0x2, 0x3}
 
-	// peer is connected to our server.
+	// client is connected to our server.
 	// events will produce events as the server receives them.
-	peer, events := createServerClient(t)
+	client, events := createServerClient(t)
 
 	// Wait for the server to respond with a connection.
-	ev := <-events
+	ev, _ := events.Next()
 	if data := ev.GetPeer().GetData(); data != nil {
 		t.Fatalf("did not expect new peer to have data set, but has %x", data)
 	}
@@ -29,14 +32,17 @@ func TestPeerData(t *testing.T) {
 	assertPeerData(t, ev.GetPeer(), testData, "immediate after set")
 
 	// Send a message to the server.
-	if err := peer.SendString("testmessage", 0, enet.PacketFlagReliable); err != nil {
+	err := client.do(func(peer enet.Peer) error {
+		return peer.SendString("testmessage", 0, enet.PacketFlagReliable)
+	})
+	if err != nil {
 		t.Fatal(err)
 	}
 
 	// Wait for the server to receive this message, then check the
 	// server-side peer associated with this event has the data
 	// we set previously.
-	ev = <-events
+	ev, _ = events.Next()
 	assertPeerData(t, ev.GetPeer(), testData, "on packet received")
 
 	t.Run("clear-data", func(t *testing.T) {
@@ -112,67 +118,95 @@ func assertPeerData(t testing.TB, peer enet.Peer, expected []byte, msg string) {
 	}
 }
 
-// createServerClient creates a dummy enet server and client. The returned
-// peer can be used to send messages to the server, and the blocking events
-// channel returned will be given each event as the server picks it up.
-func createServerClient(t *testing.T) (clientConn enet.Peer, serverEvents <-chan enet.Event) {
-	port := getFreePort()
+// testClient is a client connected to a test server. Its host is serviced on another
+// goroutine, so its peer must only be used through do.
+type testClient struct {
+	host *enet.SafeHost
+	peer enet.Peer
+}
 
-	done := make(chan bool, 0)
-	events := make(chan enet.Event)
+// do calls fn with the peer of the client on the goroutine servicing the client, and
+// returns its error.
+func (client *testClient) do(fn func(peer enet.Peer) error) error {
+	return client.host.Do(func(enet.Host) error {
+		return fn(client.peer)
+	}).Err()
+}
 
-	t.Cleanup(func() {
-		// Kill our background service routines for client & server.
-		close(done)
-	})
+// createServerClient creates a dummy enet server and client. The returned
+// client can be used to send messages to the server, and the returned
+// event stream will be given each event as the server picks it up.
+func createServerClient(t *testing.T) (client *testClient, serverEvents *enet.EventStream) {
+	port := getFreePort()
 
-	// Create a server and continuously service it, exposing any captured events.
+	// Create a server and continuously service it, exposing any captured events. The
+	// server isn't serviced while a test handles an event.
 	server, err := enet.NewHost(enet.NewListenAddress(port), 10, 1, 0, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	events := server.Events(testContext(t))
+	return connectClient(t, port, 1, nil), events
+}
+
+// testContext returns a context that is cancelled when the test ends, which stops the
+// background service routines of the test and destroys their hosts.
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// runHost continuously services a host in the background until the test ends, calling
+// handle with every event if it is not nil. The packets of receive events are destroyed
+// after handling them.
+func runHost(t *testing.T, host enet.Host, handle func(ev enet.Event)) {
+	ctx := testContext(t)
 	go func() {
-		for true {
-			select {
-			case <-done:
-				return
-			default:
-				ev := server.Service(0)
-
-				// Pass any event out to our channel. This will block
-				// until a test consumes it.
-				if ev.GetType() != enet.EventNone {
-					events <- ev
-				}
-			}
+		if err := host.Run(ctx, eventHandler(handle)); err != nil {
+			t.Error(err)
 		}
 	}()
+}
+
+// connectClient creates a client connected to the server on the given port, and
+// continuously services it in the background like runHost.
+func connectClient(t *testing.T, port uint16, channelCount int, handle func(ev enet.Event)) *testClient {
+	host, err := enet.NewHost(nil, 1, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	// Create a client and continuously service it in the background.
-	client, err := enet.NewHost(nil, 1, 1, 0, 0)
+	peer, err := host.Connect(localAddress(t, port), channelCount, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	client := &testClient{
+		host: enet.NewSafeHost(host),
+		peer: peer,
+	}
+	ctx := testContext(t)
 	go func() {
-		for true {
-			select {
-			case <-done:
-				return
-			default:
-				client.Service(0)
-			}
+		if err := client.host.Run(ctx, eventHandler(handle)); err != nil {
+			t.Error(err)
 		}
 	}()
+	return client
+}
 
-	// Connect to our server.
-	peer, err := client.Connect(enet.NewAddress("localhost", port), 1, 0)
-	if err != nil {
-		t.Fatal(err)
+// eventHandler returns an event handler that calls handle if it is not nil, and destroys
+// the packets of receive events afterwards.
+func eventHandler(handle func(ev enet.Event)) enet.EventHandler {
+	return func(ev enet.Event) {
+		if handle != nil {
+			handle(ev)
+		}
+		if ev.GetType() == enet.EventReceive {
+			ev.GetPacket().Destroy()
+		}
 	}
-
-	return peer, events
 }
 
 var port uint16 = 49152
@@ -184,6 +218,15 @@ func getFreePort() uint16 {
 	return port
 }
 
+// localAddress returns the address of a port on localhost.
+func localAddress(t testing.TB, port uint16) enet.Address {
+	addr, err := enet.NewAddress("localhost", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
 // currentMemory returns the memory usage of the current process according to
 // the OS. This uses linux's proc FS to give a rough estimate based on VmSize.
 // Note we don't want to use runtime.MemStats here as we're looking for the
@@ -207,3 +250,264 @@ func currentMemory(t testing.TB) int {
 
 	return kb
 }
+
+func TestPeerConfiguration(t *testing.T) {
+	client, _ := createServerClient(t)
+
+	// configure applies a configuration to the client peer on its service goroutine.
+	configure := func(fn func(peer enet.Peer) error) error {
+		return client.do(fn)
+	}
+
+	err := configure(func(peer enet.Peer) error {
+		return peer.SetTimeout(0, time.Second, 5*time.Second)
+	})
+	if err != nil {
+		t.Fatalf("expected valid timeout to be accepted: %s", err)
+	}
+
+	err = configure(func(peer enet.Peer) error {
+		return peer.SetTimeout(0, 5*time.Second, time.Second)
+	})
+	if err == nil {
+		t.Fatal("expected timeout minimum greater than maximum to be rejected")
+	}
+
+	// Zero values stand for the defaults, which are compared as well.
+	err = configure(func(peer enet.Peer) error {
+		return peer.SetTimeout(0, enet.PeerTimeoutMaximum+time.Second, 0)
+	})
+	if err == nil {
+		t.Fatal("expected timeout minimum greater than the default maximum to be rejected")
+	}
+
+	err = configure(func(peer enet.Peer) error {
+		return peer.SetTimeout(0, 0, enet.PeerTimeoutMinimum-time.Second)
+	})
+	if err == nil {
+		t.Fatal("expected timeout maximum less than the default minimum to be rejected")
+	}
+
+	err = configure(func(peer enet.Peer) error {
+		return peer.SetTimeout(0, -time.Second, 0)
+	})
+	if err == nil {
+		t.Fatal("expected negative timeout to be rejected")
+	}
+
+	err = configure(func(peer enet.Peer) error {
+		return peer.SetPingInterval(time.Microsecond)
+	})
+	if err == nil {
+		t.Fatal("expected sub-millisecond ping interval to be rejected")
+	}
+
+	err = configure(func(peer enet.Peer) error {
+		return peer.SetPingInterval(100 * time.Millisecond)
+	})
+	if err != nil {
+		t.Fatalf("expected valid ping interval to be accepted: %s", err)
+	}
+
+	err = configure(func(peer enet.Peer) error {
+		return peer.ConfigureThrottle(enet.ThrottleConfig{
+			Interval:     time.Second,
+			Acceleration: 2,
+			Deceleration: 2,
+		})
+	})
+	if err != nil {
+		t.Fatalf("expected valid throttle configuration to be accepted: %s", err)
+	}
+
+	err = configure(func(peer enet.Peer) error {
+		return peer.ConfigureThrottle(enet.ThrottleConfig{
+			Interval:     time.Second,
+			Acceleration: enet.PacketThrottleScale + 1,
+			Deceleration: 2,
+		})
+	})
+	if err == nil {
+		t.Fatal("expected throttle acceleration above the scale to be rejected")
+	}
+}
+
+func TestPeerSendErrors(t *testing.T) {
+	// A peer that has only just started connecting can't send yet.
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Destroy()
+
+	peer, err := host.Connect(localAddress(t, getFreePort()), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = peer.SendString("too early", 0, enet.PacketFlagReliable)
+	if !errors.Is(err, enet.ErrPeerNotConnected) {
+		t.Fatalf("expected %v, got %v", enet.ErrPeerNotConnected, err)
+	}
+
+	// Wait for the server to accept the connection. Only 1 channel was allocated.
+	_, events := createServerClient(t)
+	ev, _ := events.Next()
+
+	err = ev.GetPeer().SendString("wrong channel", 1, enet.PacketFlagReliable)
+	if !errors.Is(err, enet.ErrInvalidChannel) {
+		t.Fatalf("expected %v, got %v", enet.ErrInvalidChannel, err)
+	}
+
+	if err := ev.GetPeer().SendString("right channel", 0, enet.PacketFlagReliable); err != nil {
+		t.Fatalf("expected send on allocated channel to succeed: %s", err)
+	}
+}
+
+func TestPeerIdentity(t *testing.T) {
+	type session struct {
+		name string
+	}
+
+	client, events := createServerClient(t)
+
+	// Attach a session to the server-side peer when it connects.
+	ev, _ := events.Next()
+	serverPeer := ev.GetPeer()
+	serverPeer.SetValue(&session{name: "player"})
+
+	err := client.do(func(peer enet.Peer) error {
+		return peer.SendString("hello", 0, enet.PacketFlagReliable)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The receive event must return the same peer, with the session still attached.
+	ev, _ = events.Next()
+	ev.GetPacket().Destroy()
+
+	if ev.GetPeer() != serverPeer {
+		t.Fatal("expected receive event to return the same peer as the connect event")
+	}
+
+	sessions := map[enet.Peer]string{serverPeer: "player"}
+	if sessions[ev.GetPeer()] != "player" {
+		t.Fatal("expected peer to be usable as a map key")
+	}
+
+	if s, ok := ev.GetPeer().Value().(*session); !ok || s.name != "player" {
+		t.Fatalf("expected peer value to be the session, got %v", ev.GetPeer().Value())
+	}
+
+	// The session is still available while handling the disconnect event.
+	client.do(func(peer enet.Peer) error {
+		peer.Disconnect(0)
+		return nil
+	})
+
+	ev, _ = events.Next()
+	if ev.GetType() != enet.EventDisconnect {
+		t.Fatalf("expected disconnect event, got %d", ev.GetType())
+	}
+	if ev.GetPeer() != serverPeer {
+		t.Fatal("expected disconnect event to return the same peer as the connect event")
+	}
+	if _, ok := ev.GetPeer().Value().(*session); !ok {
+		t.Fatal("expected peer value to be available during the disconnect event")
+	}
+}
+
+func TestPeerReconnectFromDisconnect(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+	peer.SetData([]byte("old"))
+	connectID := peer.GetConnectId()
+	serverAddress := peer.GetAddress().String()
+
+	peer.Disconnect(0)
+
+	// Reconnect while handling the disconnect event, which reuses the only enet peer of
+	// the client.
+	var reconnected enet.Peer
+	deadline := time.Now().Add(5 * time.Second)
+	for reconnected == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the disconnect event")
+		}
+		if _, err := server.Service(1); err != nil {
+			t.Fatal(err)
+		}
+
+		ev, err := client.Service(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.GetType() != enet.EventDisconnect {
+			continue
+		}
+
+		if ev.GetPeer() != peer || string(peer.GetData()) != "old" {
+			t.Fatal("expected the disconnect event to return the peer with its data")
+		}
+
+		reconnected, err = client.Connect(peer.GetAddress(), 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reconnected.SetData([]byte("new"))
+	}
+
+	if reconnected == peer {
+		t.Fatal("expected a new peer for the new connection")
+	}
+
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		return ev.GetType() == enet.EventConnect
+	})
+
+	// Releasing the old peer must not touch the new connection.
+	if string(reconnected.GetData()) != "new" {
+		t.Fatalf("expected the new peer to keep its data, got %q", reconnected.GetData())
+	}
+	if reconnected.GetConnectId() == connectID {
+		t.Fatal("expected the new connection to have a new connect ID")
+	}
+	if peer.GetConnectId() != connectID || peer.GetAddress().String() != serverAddress {
+		t.Fatal("expected the old peer to keep its connect ID and address")
+	}
+	if peer.GetData() != nil {
+		t.Fatal("expected the data of the old peer to be freed")
+	}
+}
+
+func TestDestroyTwice(t *testing.T) {
+	packet, err := enet.NewPacket([]byte("hello"), enet.PacketFlagReliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := packet.Destroy(); err != nil {
+		t.Fatal(err)
+	}
+	if err := packet.Destroy(); !errors.Is(err, enet.ErrPacketDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrPacketDestroyed, err)
+	}
+	if data := packet.GetData(); data != nil {
+		t.Fatalf("expected destroyed packet to have no data, got %v", data)
+	}
+
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := host.Destroy(); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.Destroy(); !errors.Is(err, enet.ErrHostDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrHostDestroyed, err)
+	}
+	if _, err := host.Service(0); !errors.Is(err, enet.ErrHostDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrHostDestroyed, err)
+	}
+}
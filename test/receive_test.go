@@ -0,0 +1,59 @@
+package enet_test
+
+import (
+	"fmt"
+	"github.com/codecat/go-enet"
+	"testing"
+	"time"
+)
+
+func TestPeerReceiveQueue(t *testing.T) {
+	port := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(port), 1, 2, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetReceiveQueueing(true)
+
+	// Queued packets can be received from any goroutine, so the server runs on its own.
+	connected := make(chan enet.Peer, 1)
+	runHost(t, server, func(ev enet.Event) {
+		if ev.GetType() == enet.EventConnect {
+			connected <- ev.GetPeer()
+		}
+	})
+
+	client := connectClient(t, port, 2, nil)
+	serverPeer := <-connected
+
+	for i := 0; i < 3; i++ {
+		err := client.host.SendBytes(client.peer, []byte(fmt.Sprintf("message %d", i)), 1, enet.PacketFlagReliable).Err()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Pull the packets from the server-side peer, without receive events.
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; i < 3; {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+
+		packet, channel, ok := serverPeer.Receive()
+		if !ok {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+
+		if channel != 1 {
+			t.Fatalf("expected packet on channel 1, got %d", channel)
+		}
+		if data, expected := string(packet.GetData()), fmt.Sprintf("message %d", i); data != expected {
+			t.Fatalf("expected %q, got %q", expected, data)
+		}
+		packet.Destroy()
+		i++
+	}
+}
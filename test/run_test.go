@@ -0,0 +1,29 @@
+package enet_test
+
+import (
+	"context"
+	"github.com/codecat/go-enet"
+	"testing"
+)
+
+func TestEventsStop(t *testing.T) {
+	host, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := host.Events(ctx)
+	cancel()
+
+	// The stream ends once the host has been destroyed.
+	if ev, ok := events.Next(); ok {
+		t.Fatalf("expected no more events, got %d", ev.GetType())
+	}
+	if err := events.Err(); err != nil {
+		t.Fatalf("expected no error when cancelled, got %v", err)
+	}
+	if _, err := host.Service(0); err == nil {
+		t.Fatal("expected host to be destroyed once the stream has ended")
+	}
+}
@@ -0,0 +1,83 @@
+package enet_test
+
+import (
+	"context"
+	"errors"
+	"github.com/codecat/go-enet"
+	"sync"
+	"testing"
+)
+
+func TestSafeHostConcurrentSends(t *testing.T) {
+	port := getFreePort()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, err := enet.NewHost(enet.NewListenAddress(port), 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := server.Events(ctx)
+
+	client, err := enet.NewHost(nil, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	safe := enet.NewSafeHost(client)
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- safe.Run(ctx, func(ev enet.Event) {
+			if ev.GetType() == enet.EventReceive {
+				ev.GetPacket().Destroy()
+			}
+		})
+	}()
+
+	peer, err := safe.Connect(localAddress(t, port), 1, 0).Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ev, _ := events.Next(); ev.GetType() != enet.EventConnect {
+		t.Fatalf("expected connect event, got %d", ev.GetType())
+	}
+
+	// Send from many goroutines at once. All sends are executed on the service goroutine.
+	const senders = 10
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := safe.SendBytes(peer, []byte("hello"), 0, enet.PacketFlagReliable).Err(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for received := 0; received < senders; {
+		ev, _ := events.Next()
+		if ev.GetType() != enet.EventReceive {
+			continue
+		}
+		if data := string(ev.GetPacket().GetData()); data != "hello" {
+			t.Fatalf("expected to receive hello, got %q", data)
+		}
+		ev.GetPacket().Destroy()
+		received++
+	}
+
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the host has been destroyed, commands fail instead of blocking forever.
+	err = safe.Do(func(enet.Host) error { return nil }).Err()
+	if !errors.Is(err, enet.ErrHostDestroyed) {
+		t.Fatalf("expected %v, got %v", enet.ErrHostDestroyed, err)
+	}
+}
@@ -0,0 +1,126 @@
+package enet_test
+
+import (
+	"github.com/codecat/go-enet"
+	"testing"
+)
+
+func TestPeerStateString(t *testing.T) {
+	if s := enet.PeerStateConnectionPending.String(); s != "connection pending" {
+		t.Fatalf("expected connection pending, got %q", s)
+	}
+	if s := enet.PeerState(42).String(); s != "PeerState(42)" {
+		t.Fatalf("expected unknown state to include its value, got %q", s)
+	}
+}
+
+func TestStateChangeCallback(t *testing.T) {
+	port := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(port), 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connected := make(chan enet.Peer, 1)
+	server.SetStateChangeCallback(func(peer enet.Peer, from, to enet.PeerState) {
+		if to == enet.PeerStateConnected {
+			connected <- peer
+		}
+	})
+	events := server.Events(testContext(t))
+
+	connectClient(t, port, 1, nil)
+
+	ev, _ := events.Next()
+	if ev.GetType() != enet.EventConnect {
+		t.Fatalf("expected connect event, got %d", ev.GetType())
+	}
+
+	// The callback reports the same peer as the connect event.
+	if peer := <-connected; peer != ev.GetPeer() {
+		t.Fatal("expected state change to report the peer of the connect event")
+	}
+
+	if state := ev.GetPeer().State(); state != enet.PeerStateConnected {
+		t.Fatalf("expected peer to be connected, got %s", state)
+	}
+}
+
+func TestStateChangeCallbackExistingPeers(t *testing.T) {
+	server, client, peer := connectHosts(t, 1, nil)
+
+	type change struct {
+		from, to enet.PeerState
+	}
+	var changes []change
+	server.SetStateChangeCallback(func(_ enet.Peer, from, to enet.PeerState) {
+		changes = append(changes, change{from, to})
+	})
+
+	// The connection existed before the callback was set, so only its end is reported.
+	peer.Disconnect(0)
+	serviceUntil(t, server, client, func(ev enet.Event) bool {
+		return ev.GetType() == enet.EventDisconnect
+	})
+
+	if len(changes) == 0 {
+		t.Fatal("expected the disconnection to be reported")
+	}
+	if changes[0].from != enet.PeerStateConnected {
+		t.Fatalf("expected the first change to start from connected, got %+v", changes)
+	}
+}
+
+func TestHostPeers(t *testing.T) {
+	port := getFreePort()
+
+	server, err := enet.NewHost(enet.NewListenAddress(port), 2, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Destroy()
+
+	if n := server.ConnectedPeerCount(); n != 0 {
+		t.Fatalf("expected no connected peers, got %d", n)
+	}
+
+	connectClient(t, port, 1, nil)
+
+	var ev enet.Event
+	for ev == nil || ev.GetType() != enet.EventConnect {
+		if ev, err = server.Service(1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	peers := server.Peers()
+	if len(peers) != 1 || peers[0] != ev.GetPeer() {
+		t.Fatalf("expected the connected peer, got %v", peers)
+	}
+	if n := server.ConnectedPeerCount(); n != 1 {
+		t.Fatalf("expected 1 connected peer, got %d", n)
+	}
+
+	slots := server.AllPeers()
+	if len(slots) != 2 {
+		t.Fatalf("expected 2 peer slots, got %d", len(slots))
+	}
+	connected := 0
+	for _, slot := range slots {
+		switch slot.State {
+		case enet.PeerStateConnected:
+			connected++
+			if slot.Peer != ev.GetPeer() {
+				t.Fatal("expected slot to hold the peer of the connect event")
+			}
+		case enet.PeerStateDisconnected:
+			if slot.Peer != nil {
+				t.Fatal("expected disconnected slot to have no peer")
+			}
+		}
+	}
+	if connected != 1 {
+		t.Fatalf("expected 1 connected slot, got %d", connected)
+	}
+}
@@ -0,0 +1,66 @@
+package enet_test
+
+import (
+	"github.com/codecat/go-enet"
+	"testing"
+)
+
+func TestPeerStats(t *testing.T) {
+	client, events := createServerClient(t)
+
+	// Wait for the server to accept the connection.
+	ev, _ := events.Next()
+
+	stats := ev.GetPeer().Stats()
+
+	if stats.MTU == 0 {
+		t.Fatalf("expected connected peer to have a negotiated mtu")
+	}
+
+	if stats.WindowSize == 0 {
+		t.Fatalf("expected connected peer to have a negotiated window size")
+	}
+
+	if stats.PacketLoss < 0 || stats.PacketLoss > 1 {
+		t.Fatalf("expected packet loss to be a ratio between 0 and 1, got %f", stats.PacketLoss)
+	}
+
+	if stats.PacketThrottle < 0 || stats.PacketThrottle > 1 {
+		t.Fatalf("expected packet throttle to be a ratio between 0 and 1, got %f", stats.PacketThrottle)
+	}
+
+	if stats.RoundTripTime < 0 {
+		t.Fatalf("expected round trip time to be positive, got %s", stats.RoundTripTime)
+	}
+
+	var clientStats enet.PeerStats
+	client.do(func(peer enet.Peer) error {
+		clientStats = peer.Stats()
+		return nil
+	})
+	if clientStats.MTU == 0 {
+		t.Fatalf("expected client peer to have a negotiated mtu")
+	}
+}
+
+func TestHostStats(t *testing.T) {
+	_, client, _ := connectHosts(t, 1, nil)
+
+	stats := client.Stats()
+	if stats.TotalSentPackets == 0 || stats.TotalSentData == 0 {
+		t.Fatalf("expected client to have sent data, got %+v", stats)
+	}
+	if stats.TotalReceivedPackets == 0 || stats.TotalReceivedData == 0 {
+		t.Fatalf("expected client to have received data, got %+v", stats)
+	}
+
+	// Reset returns the last snapshot and starts counting from 0 again.
+	reset := client.ResetStats()
+	if reset.TotalSentData < stats.TotalSentData {
+		t.Fatalf("expected reset snapshot to include previous counters, got %+v", reset)
+	}
+
+	if after := client.Stats(); after.TotalSentData != 0 || after.TotalReceivedData != 0 {
+		t.Fatalf("expected counters to be 0 after reset, got %+v", after)
+	}
+}